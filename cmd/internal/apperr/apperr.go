@@ -0,0 +1,210 @@
+// Package apperr defines the typed errors returned by the handlers and the
+// services. They are rendered centrally as RFC 7807 problem details, each
+// with a stable machine readable code.
+package apperr
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindNotFound
+	KindConflict
+	// KindUnprocessable is a well-formed request that can not be carried
+	// out, such as a song unknown to the external API.
+	KindUnprocessable
+	// KindUpstream is a failed or invalid answer of the external API.
+	KindUpstream
+	KindUnavailable
+	KindTimeout
+)
+
+// Status returns the HTTP status code of the kind.
+func (k Kind) Status() int {
+	switch k {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	case KindUpstream:
+		return http.StatusBadGateway
+	case KindUnavailable:
+		return http.StatusServiceUnavailable
+	case KindTimeout:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+// FieldError describes why one field of the request is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type Error struct {
+	Kind Kind
+	// Code identifies the error for the clients and never changes.
+	Code string
+	// Detail is the message shown to the client.
+	Detail string
+	Fields []FieldError
+	// Extra members added to the problem, such as the number of songs that
+	// prevent a group from being deleted.
+	Extra map[string]any
+	// Err is the cause, it is logged but never shown to the client.
+	Err error
+
+	// status overrides the status of the kind for the fiber errors.
+	status int
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Detail + ": " + e.Err.Error()
+	}
+	return e.Detail
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports errors with the same code as equal, so the package level
+// errors still match once a cause or extra members were added.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+func (e *Error) Status() int {
+	if e.status != 0 {
+		return e.status
+	}
+	return e.Kind.Status()
+}
+
+// Wrap returns a copy of e caused by err.
+func (e *Error) Wrap(err error) *Error {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// With returns a copy of e with an extra problem member.
+func (e *Error) With(key string, value any) *Error {
+	c := *e
+	c.Extra = maps.Clone(e.Extra)
+	if c.Extra == nil {
+		c.Extra = make(map[string]any)
+	}
+	c.Extra[key] = value
+	return &c
+}
+
+func Validation(code, detail string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: code, Detail: detail, Fields: fields}
+}
+
+func NotFound(code, detail string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Detail: detail}
+}
+
+func Conflict(code, detail string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Detail: detail}
+}
+
+func Unprocessable(code, detail string) *Error {
+	return &Error{Kind: KindUnprocessable, Code: code, Detail: detail}
+}
+
+func Upstream(code, detail string, err error) *Error {
+	return &Error{Kind: KindUpstream, Code: code, Detail: detail, Err: err}
+}
+
+func Unavailable(code, detail string, err error) *Error {
+	return &Error{Kind: KindUnavailable, Code: code, Detail: detail, Err: err}
+}
+
+func Timeout(code, detail string, err error) *Error {
+	return &Error{Kind: KindTimeout, Code: code, Detail: detail, Err: err}
+}
+
+func Internal(code, detail string, err error) *Error {
+	return &Error{Kind: KindInternal, Code: code, Detail: detail, Err: err}
+}
+
+// Codes of the errors made by From.
+const (
+	CodeInternal       = "internal_error"
+	CodeRouteNotFound  = "route_not_found"
+	CodeRequestTimeout = "request_timeout"
+	CodeCanceled       = "request_canceled"
+)
+
+// From returns err as an *Error. Fiber errors keep their status, an expired
+// request context becomes a timeout and anything else an internal error.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fromFiber(fiberErr)
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return Timeout(CodeRequestTimeout, "Request timed out", err)
+	case errors.Is(err, context.Canceled):
+		return Unavailable(CodeCanceled, "Request was canceled", err)
+	}
+
+	return Internal(CodeInternal, "Internal server error", err)
+}
+
+func fromFiber(err *fiber.Error) *Error {
+	code := strings.ReplaceAll(strings.ToLower(http.StatusText(err.Code)), " ", "_")
+	switch {
+	case err.Code == fiber.StatusNotFound:
+		code = CodeRouteNotFound
+	case code == "":
+		code = CodeInternal
+	}
+
+	e := &Error{Code: code, Detail: err.Message, Err: err}
+	switch {
+	case err.Code == fiber.StatusNotFound:
+		e.Kind = KindNotFound
+	case err.Code == fiber.StatusServiceUnavailable:
+		e.Kind = KindUnavailable
+	case err.Code == fiber.StatusGatewayTimeout || err.Code == fiber.StatusRequestTimeout:
+		e.Kind = KindTimeout
+	case err.Code >= 400 && err.Code < 500:
+		e.Kind = KindValidation
+	}
+
+	// Statuses without a kind of their own, such as 405 or 413, are kept.
+	if e.Status() != err.Code {
+		e.status = err.Code
+	}
+
+	return e
+}
@@ -0,0 +1,80 @@
+package apperr
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+)
+
+// ContentType of the problem details, RFC 7807.
+const ContentType = "application/problem+json"
+
+// typePrefix makes the problem type URI out of the error code.
+const typePrefix = "urn:problem:songs-api:"
+
+// members are the JSON names of the standard members of Problem.
+var members = []string{"type", "title", "status", "detail", "instance", "code", "request_id", "errors"}
+
+// Problem is the body of every error response.
+type Problem struct {
+	Type      string       `json:"type" example:"urn:problem:songs-api:song_not_found"`
+	Title     string       `json:"title" example:"Not Found"`
+	Status    int          `json:"status" example:"404"`
+	Detail    string       `json:"detail,omitempty" example:"Song not found"`
+	Instance  string       `json:"instance,omitempty" example:"/api/song/42"`
+	Code      string       `json:"code" example:"song_not_found"`
+	RequestID string       `json:"request_id,omitempty" example:"5f0c6a5e-8a8e-4a53-9a51-8f2f7a0bd5e1"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	// Extra members are written next to the standard ones.
+	Extra map[string]any `json:"-"`
+}
+
+// Problem returns the problem details of e for the request to instance.
+func (e *Error) Problem(instance, requestID string) Problem {
+	status := e.Status()
+	return Problem{
+		Type:      typePrefix + e.Code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    e.Detail,
+		Instance:  instance,
+		Code:      e.Code,
+		RequestID: requestID,
+		Errors:    e.Fields,
+		Extra:     e.Extra,
+	}
+}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	// problem has the fields of Problem without its methods.
+	type problem Problem
+	b, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extra) == 0 {
+		return b, err
+	}
+
+	// The extra members follow the standard ones, which they can not replace.
+	b = b[:len(b)-1]
+	for _, key := range slices.Sorted(maps.Keys(p.Extra)) {
+		if slices.Contains(members, key) {
+			continue
+		}
+
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(p.Extra[key])
+		if err != nil {
+			return nil, err
+		}
+
+		b = append(b, ',')
+		b = append(b, name...)
+		b = append(b, ':')
+		b = append(b, value...)
+	}
+
+	return append(b, '}'), nil
+}
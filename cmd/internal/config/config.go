@@ -3,28 +3,128 @@ package config
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
 type Config struct {
-	Env         string `yaml:"env" env-default:"dev" env-requried:"true"`
-	ExternalAPI string `yaml:"external_api"`
-	HTTPServer  `yaml:"http_server"`
-	Database    `yaml:"database"`
+	Env            string `yaml:"env" env-default:"dev" env-requried:"true"`
+	Logging        `yaml:"logging"`
+	ExternalAPI    string `yaml:"external_api"`
+	ExternalClient `yaml:"external_client"`
+	Enrichment     `yaml:"enrichment"`
+	Trash          `yaml:"trash"`
+	Health         `yaml:"health"`
+	Tracing        `yaml:"tracing"`
+	HTTPServer     `yaml:"http_server"`
+	Database       `yaml:"database"`
+}
+
+type Logging struct {
+	// Level is debug, info, warn or error. Empty picks debug for the dev env
+	// and info otherwise.
+	Level  string `yaml:"level"`
+	Format string `yaml:"format" env-default:"json"` // json | text
+	// Output is stdout, stderr or the path of a file rotated by size.
+	Output     string `yaml:"output" env-default:"stdout"`
+	MaxSizeMB  int    `yaml:"max_size_mb" env-default:"100"`
+	MaxBackups int    `yaml:"max_backups" env-default:"5"`
+	MaxAgeDays int    `yaml:"max_age_days" env-default:"30"`
+	Compress   bool   `yaml:"compress" env-default:"false"`
+	// Redact lists the attribute keys whose values are replaced in the logs.
+	// Defaults to password, authorization and token, [] redacts nothing.
+	Redact []string `yaml:"redact"`
+	// MaxAttrSize truncates longer attribute values, 0 disables it. Defaults
+	// to 1024.
+	MaxAttrSize int `yaml:"max_attr_size"`
+}
+
+type ExternalClient struct {
+	Timeout     time.Duration `yaml:"timeout" env-default:"5s"`
+	MaxRetries  int           `yaml:"max_retries" env-default:"3"`
+	BackoffBase time.Duration `yaml:"backoff_base" env-default:"200ms"`
+	BackoffMax  time.Duration `yaml:"backoff_max" env-default:"2s"`
+	// BreakerThreshold is the number of failed lookups in a row that opens
+	// the circuit breaker, 0 disables it. Defaults to 5.
+	BreakerThreshold int           `yaml:"breaker_threshold"`
+	BreakerTimeout   time.Duration `yaml:"breaker_timeout" env-default:"30s"`
+	// DegradedMode stores new songs without details while the external API is
+	// unavailable instead of failing the request.
+	DegradedMode bool `yaml:"degraded_mode" env-default:"false"`
+}
+
+type Enrichment struct {
+	// Async makes CreateSong and UpdateSongInfo answer 202 and leave the
+	// external lookup to the background workers. Defaults to true.
+	Async        bool          `yaml:"async"`
+	Workers      int           `yaml:"workers" env-default:"2"`
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"1s"`
+	Lease        time.Duration `yaml:"lease" env-default:"1m"`
+	MaxAttempts  int           `yaml:"max_attempts" env-default:"5"`
+	RetryDelay   time.Duration `yaml:"retry_delay" env-default:"5s"`
+	MaxDelay     time.Duration `yaml:"max_retry_delay" env-default:"5m"`
+}
+
+type Trash struct {
+	// Retention is how long deleted songs stay in the trash before they are
+	// purged automatically, 0 keeps them until purged by hand. Defaults to
+	// 720h.
+	Retention     time.Duration `yaml:"retention"`
+	PurgeInterval time.Duration `yaml:"purge_interval" env-default:"1h"`
+}
+
+type Health struct {
+	// Timeout bounds each readiness check.
+	Timeout time.Duration `yaml:"timeout" env-default:"2s"`
+	// ExternalAPI adds the reachability of the external API to the readiness
+	// report. It is optional: when it is down the service is degraded, not
+	// unready. The result is cached for ExternalAPITTL.
+	ExternalAPI    bool          `yaml:"external_api" env-default:"false"`
+	ExternalAPITTL time.Duration `yaml:"external_api_ttl" env-default:"30s"`
+}
+
+type Tracing struct {
+	// Exporter is where the spans are sent: none, stdout, file or otlp.
+	Exporter string `yaml:"exporter" env-default:"none"`
+	// Endpoint is the host:port of the OTLP/HTTP collector, empty falls back
+	// to OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4318.
+	Endpoint string `yaml:"endpoint"`
+	Insecure bool   `yaml:"insecure" env-default:"false"`
+	// File is the path the file exporter appends the spans to.
+	File string `yaml:"file" env-default:"traces.json"`
+	// SampleRatio is the share of new traces that are recorded, requests
+	// carrying a sampled trace context are always recorded. Defaults to 1.
+	SampleRatio float64 `yaml:"sample_ratio"`
 }
 
 type HTTPServer struct {
 	Port string `yaml:"port" env-default:":8080"`
+	// RequestTimeout bounds the database queries and external API calls of a
+	// request, 0 disables it. Defaults to 10s. Client disconnects are not
+	// detected, an abandoned request runs until it ends or times out.
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+	ReadTimeout    time.Duration `yaml:"read_timeout" env-default:"10s"`
+	WriteTimeout   time.Duration `yaml:"write_timeout" env-default:"15s"`
+	IdleTimeout    time.Duration `yaml:"idle_timeout" env-default:"60s"`
+	// ShutdownTimeout is how long in-flight requests are given to finish
+	// once the server is asked to stop.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env-default:"15s"`
+	// BodyLimit is the maximum request body size in bytes.
+	BodyLimit int `yaml:"body_limit" env-default:"4194304"`
 }
 
 type Database struct {
+	Driver   string `yaml:"driver" env-default:"postgres"`
 	Name     string `yaml:"db_name"`
 	Host     string `yaml:"db_host"`
 	Username string `yaml:"db_username"`
 	Password string `yaml:"db_password"`
 	Port     string `yaml:"db_port"`
 	SSLMode  string `yaml:"ssl_mode"`
+	// AutoMigrate applies pending migrations on startup instead of refusing
+	// to run against an out-of-date schema.
+	AutoMigrate bool `yaml:"auto_migrate" env-default:"false"`
 }
 
 func MustLoad() *Config {
@@ -38,7 +138,7 @@ func MustLoad() *Config {
 		log.Fatalf("Config file is not exists: %s", err.Error())
 	}
 
-	var cfg Config
+	cfg := defaultConfig()
 
 	if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
 		log.Fatalf("Cannot read config: %s", err.Error())
@@ -47,3 +147,18 @@ func MustLoad() *Config {
 	log.Println("Config was read successfully")
 	return &cfg
 }
+
+// defaultConfig holds the defaults of the settings whose zero value is
+// meaningful. cleanenv applies env-default to any field still zero after the
+// file is read, so these are set before reading it instead.
+func defaultConfig() Config {
+	var cfg Config
+	cfg.Logging.Redact = []string{"password", "authorization", "token"}
+	cfg.Logging.MaxAttrSize = 1024
+	cfg.ExternalClient.BreakerThreshold = 5
+	cfg.Enrichment.Async = true
+	cfg.Trash.Retention = 720 * time.Hour
+	cfg.Tracing.SampleRatio = 1
+	cfg.HTTPServer.RequestTimeout = 10 * time.Second
+	return cfg
+}
@@ -0,0 +1,213 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/config"
+	"github.com/qwaq-dev/test-api/cmd/internal/musicinfo"
+	"github.com/qwaq-dev/test-api/cmd/internal/repository"
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+	"github.com/qwaq-dev/test-api/pkg/logger/sl"
+)
+
+// revisionAuthor is the author of the song revisions made by the workers.
+const revisionAuthor = "enrichment"
+
+// Queue fetches song details from the external API in the background.
+// Jobs are stored by the EnrichmentRepository, so they survive restarts.
+type Queue struct {
+	log      *slog.Logger
+	cfg      config.Enrichment
+	jobs     repository.EnrichmentRepository
+	songs    repository.SongRepository
+	repo     *repository.Repository
+	songInfo *musicinfo.Client
+	wakeup   chan struct{}
+}
+
+func NewQueue(log *slog.Logger, cfg config.Enrichment, repo *repository.Repository, songInfo *musicinfo.Client) *Queue {
+	return &Queue{
+		log:      log,
+		cfg:      cfg,
+		jobs:     repo.Enrichment,
+		songs:    repo.Songs,
+		repo:     repo,
+		songInfo: songInfo,
+		wakeup:   make(chan struct{}, 1),
+	}
+}
+
+// Status returns the latest job of the song, or nil if it has never been queued.
+func (q *Queue) Status(ctx context.Context, songID int) (*structure.EnrichmentJob, error) {
+	job, err := q.jobs.Latest(ctx, songID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+
+	return job, err
+}
+
+// Wake makes an idle worker look for due jobs right away. It is called once
+// the transaction that enqueued a job is committed.
+func (q *Queue) Wake() {
+	select {
+	case q.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// Run starts the workers and blocks until ctx is cancelled and all of them
+// have finished their current job.
+func (q *Queue) Run(ctx context.Context) {
+	workers := q.cfg.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	q.log.Info("Enrichment workers started", slog.Int("workers", workers))
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+
+	wg.Wait()
+	q.log.Info("Enrichment workers stopped")
+}
+
+func (q *Queue) work(ctx context.Context) {
+	interval := q.cfg.PollInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for q.runNext(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wakeup:
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNext claims and processes a single job and reports whether there was one.
+func (q *Queue) runNext(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	jobs, err := q.jobs.Claim(ctx, 1, q.cfg.Lease)
+	if err != nil {
+		if ctx.Err() == nil {
+			q.log.Error("Failed to claim enrichment job", sl.Err(err))
+		}
+		return false
+	}
+
+	if len(jobs) == 0 {
+		return false
+	}
+
+	q.process(ctx, &jobs[0])
+	return true
+}
+
+func (q *Queue) process(ctx context.Context, job *structure.EnrichmentJob) {
+	log := q.log.With(sl.ID("job", job.ID), sl.ID("song", job.SongID), slog.Int("attempt", job.Attempts))
+
+	song, err := q.songs.GetByID(ctx, job.SongID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			q.dead(ctx, log, job, "song was deleted")
+			return
+		}
+		q.retry(ctx, log, job, err)
+		return
+	}
+
+	info, err := q.songInfo.Info(ctx, song.Group.Name, song.Song)
+	if err != nil {
+		if ctx.Err() != nil {
+			// Shutting down, the lease expires and the job is picked up again.
+			return
+		}
+		if errors.Is(err, musicinfo.ErrNotFound) {
+			q.dead(ctx, log, job, err.Error())
+			return
+		}
+		q.retry(ctx, log, job, err)
+		return
+	}
+
+	releaseDate, err := structure.ParseDate(info.ReleaseDate)
+	if err != nil {
+		log.Warn("Unknown release date format, leaving it empty", sl.Err(err))
+	}
+
+	details := structure.SongDetails{
+		ReleaseDate: releaseDate,
+		Text:        info.Text,
+		Link:        info.Link,
+	}
+
+	err = q.repo.Atomic(ctx, func(tx *repository.Repository) error {
+		if err := tx.Enrichment.Complete(ctx, job, &details); err != nil {
+			return err
+		}
+
+		_, err := tx.Revisions.Record(ctx, job.SongID, structure.RevisionEnrichment, revisionAuthor)
+		return err
+	})
+	if errors.Is(err, repository.ErrLeaseLost) {
+		log.Warn("Enrichment job was claimed again, dropping its result")
+		return
+	}
+	if err != nil {
+		// The job stays running and is picked up again once its lease expires.
+		log.Error("Failed to store song details", sl.Err(err))
+		return
+	}
+
+	log.Info("Song details enriched")
+}
+
+func (q *Queue) retry(ctx context.Context, log *slog.Logger, job *structure.EnrichmentJob, cause error) {
+	if job.Attempts >= q.cfg.MaxAttempts {
+		q.dead(ctx, log, job, cause.Error())
+		return
+	}
+
+	delay := q.cfg.RetryDelay << (job.Attempts - 1)
+	if delay <= 0 || delay > q.cfg.MaxDelay {
+		delay = q.cfg.MaxDelay
+	}
+
+	log.Warn("Enrichment failed, will retry", sl.Err(cause), sl.Duration("delay", delay))
+
+	if err := q.jobs.Retry(ctx, job, time.Now().Add(delay), cause.Error()); err != nil {
+		log.Error("Failed to reschedule enrichment job", sl.Err(err))
+	}
+}
+
+func (q *Queue) dead(ctx context.Context, log *slog.Logger, job *structure.EnrichmentJob, reason string) {
+	log.Error("Enrichment failed permanently", slog.String("reason", reason))
+
+	if err := q.jobs.Dead(ctx, job, reason); err != nil {
+		log.Error("Failed to mark enrichment job as dead", sl.Err(err))
+	}
+}
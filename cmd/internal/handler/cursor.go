@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/repository"
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+)
+
+var errInvalidCursor = invalidField("invalid_cursor", "cursor", "Invalid cursor", "Expected a next_cursor or prev_cursor issued for the same sort order")
+
+// cursor is the decoded form of the opaque next_cursor/prev_cursor values.
+// It keeps the sort order it was issued for and the sort key of the song.
+type cursor struct {
+	ID       int      `json:"id"`
+	Sort     string   `json:"s,omitempty"`
+	Values   []string `json:"v,omitempty"`
+	Backward bool     `json:"b,omitempty"`
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor decodes the cursor and checks that it was issued for the
+// given sort order. The JSON types already make the ID a number and the
+// titles and group names strings, release dates are checked and normalized
+// to YYYY-MM-DD before they reach the database.
+func decodeCursor(s string, sort []repository.SortField) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID < 1 {
+		return nil, errInvalidCursor
+	}
+
+	if c.Sort != sortString(sort) || len(c.Values) != len(sort) {
+		return nil, errInvalidCursor
+	}
+
+	for i, field := range sort {
+		if field.Field != repository.SortReleaseDate {
+			continue
+		}
+
+		date, err := structure.ParseDate(c.Values[i])
+		if err != nil || strings.TrimSpace(c.Values[i]) == "" {
+			return nil, errInvalidCursor
+		}
+		c.Values[i] = date.Format(time.DateOnly)
+	}
+
+	return &c, nil
+}
+
+func (c *cursor) keyset() *repository.Keyset {
+	if c == nil {
+		return nil
+	}
+	return &repository.Keyset{ID: c.ID, Values: c.Values, Backward: c.Backward}
+}
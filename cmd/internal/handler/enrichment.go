@@ -0,0 +1,37 @@
+package handler
+
+import "github.com/gofiber/fiber/v2"
+
+// @Summary      Статус получения деталей песни
+// @Description  Возвращает статус фонового получения даты выпуска, текста и ссылки песни из внешнего API и последнюю задачу очереди.
+// @Tags         Songs
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int                true  "ID песни"
+// @Success      200  {object}  map[string]interface{}  "Статус получения деталей"
+// @Failure      400  {object}  apperr.Problem     "Некорректный ID"
+// @Failure      404  {object}  apperr.Problem     "Песня не найдена"
+// @Failure      500  {object}  apperr.Problem     "Ошибка сервера"
+// @Router       /api/song/{id}/enrichment [get]
+func (h *Handler) SongEnrichment(c *fiber.Ctx) error {
+	id, err := songID(c)
+	if err != nil {
+		return err
+	}
+
+	song, err := h.songs.GetByID(c.UserContext(), id)
+	if err != nil {
+		return songError("Failed to get song", err)
+	}
+
+	job, err := h.enrichment.Status(c.UserContext(), id)
+	if err != nil {
+		return internalError("Failed to get enrichment status", err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"song_id": id,
+		"status":  song.EnrichmentStatus,
+		"job":     job,
+	})
+}
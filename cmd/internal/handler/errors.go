@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/qwaq-dev/test-api/cmd/internal/apperr"
+	"github.com/qwaq-dev/test-api/cmd/internal/middleware"
+	"github.com/qwaq-dev/test-api/cmd/internal/repository"
+	"github.com/qwaq-dev/test-api/cmd/internal/service"
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+	"github.com/qwaq-dev/test-api/pkg/logger/sl"
+)
+
+var (
+	errInvalidBody    = apperr.Validation("invalid_body", "Failed to parse request body")
+	errInvalidSongID  = invalidField("invalid_song_id", "id", "Invalid song ID", "Expected a positive integer")
+	errPageOutOfRange = invalidField("page_out_of_range", "page", "Page out of range", "The page is past the last one")
+	errNoSongText     = apperr.NotFound("song_text_not_found", "No text available for this song")
+)
+
+// ErrorHandler renders the errors returned by the handlers and middlewares
+// as problem details and logs them, internal errors with their cause.
+func ErrorHandler(log *slog.Logger) fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		appErr := apperr.From(err)
+
+		level := slog.LevelDebug
+		switch appErr.Kind {
+		case apperr.KindInternal:
+			level = slog.LevelError
+		case apperr.KindUpstream, apperr.KindUnavailable, apperr.KindTimeout:
+			level = slog.LevelWarn
+		}
+		middleware.Logger(c, log).Log(c.UserContext(), level, "Request failed",
+			slog.String("code", appErr.Code),
+			sl.Err(appErr.Err),
+		)
+
+		problem := appErr.Problem(c.Path(), middleware.GetRequestID(c))
+		if err := c.Status(problem.Status).JSON(problem, apperr.ContentType); err != nil {
+			return c.SendStatus(problem.Status)
+		}
+
+		return nil
+	}
+}
+
+// invalidField returns the validation error of a single request field.
+func invalidField(code, field, detail, message string) *apperr.Error {
+	return apperr.Validation(code, detail, apperr.FieldError{
+		Field:   field,
+		Code:    "invalid",
+		Message: message,
+	})
+}
+
+// bodyError reports a song body that could not be parsed, an invalid release
+// date as a field error.
+func bodyError(err error) error {
+	if errors.Is(err, structure.ErrInvalidDate) {
+		return invalidField("invalid_release_date", "release_date", "Invalid release date", err.Error())
+	}
+	return errInvalidBody.Wrap(err)
+}
+
+// songError reports a missing song as not found and any other failure with
+// internalError.
+func songError(detail string, err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return service.ErrSongNotFound.Wrap(err)
+	}
+	return internalError(detail, err)
+}
+
+// internalError keeps the typed errors of the services and turns any other
+// error into an internal one described by detail.
+func internalError(detail string, err error) error {
+	var appErr *apperr.Error
+	if errors.As(err, &appErr) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	return apperr.Internal(apperr.CodeInternal, detail, err)
+}
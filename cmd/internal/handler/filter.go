@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/qwaq-dev/test-api/cmd/internal/apperr"
+	"github.com/qwaq-dev/test-api/cmd/internal/repository"
+)
+
+// invalidQuery returns the validation error of the query parameter key.
+func invalidQuery(key, message string) error {
+	return invalidField("invalid_query", key, fmt.Sprintf("Invalid %s", key), message)
+}
+
+// songFilter reads the filters and the sort order of AllSongs from the query.
+func songFilter(c *fiber.Ctx) (repository.SongFilter, error) {
+	filter := repository.SongFilter{
+		Song:  c.Query("song"),
+		Group: c.Query("group"),
+	}
+
+	if raw := c.Query("group_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id < 1 {
+			return filter, invalidQuery("group_id", "Expected a positive integer")
+		}
+		filter.GroupID = id
+	}
+
+	var err error
+	if filter.ReleasedAfter, err = queryDate(c, "released_after"); err != nil {
+		return filter, err
+	}
+	if filter.ReleasedBefore, err = queryDate(c, "released_before"); err != nil {
+		return filter, err
+	}
+	if filter.HasText, err = queryBool(c, "has_text"); err != nil {
+		return filter, err
+	}
+	if filter.HasLink, err = queryBool(c, "has_link"); err != nil {
+		return filter, err
+	}
+	if filter.Sort, err = parseSort(c.Query("sort")); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+func queryDate(c *fiber.Ctx, key string) (*time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return nil, invalidQuery(key, "Expected a YYYY-MM-DD date")
+	}
+
+	return &t, nil
+}
+
+func queryBool(c *fiber.Ctx, key string) (*bool, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, invalidQuery(key, "Expected true or false")
+	}
+
+	return &b, nil
+}
+
+// parseSort parses a comma separated list of fields, a leading "-" sorts the
+// field in descending order: "-release_date,title".
+func parseSort(raw string) ([]repository.SortField, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var (
+		fields  []repository.SortField
+		invalid []apperr.FieldError
+	)
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		field := repository.SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+
+		switch {
+		case !repository.IsSortField(field.Field):
+			invalid = append(invalid, apperr.FieldError{
+				Field:   "sort",
+				Code:    "unknown_field",
+				Message: fmt.Sprintf("Unknown sort field %q, expected one of title, group, release_date", field.Field),
+			})
+		case seen[field.Field]:
+			invalid = append(invalid, apperr.FieldError{
+				Field:   "sort",
+				Code:    "duplicate_field",
+				Message: fmt.Sprintf("Duplicate sort field %q", field.Field),
+			})
+		}
+
+		seen[field.Field] = true
+		fields = append(fields, field)
+	}
+
+	if len(invalid) > 0 {
+		return nil, apperr.Validation("invalid_sort", "Invalid sort", invalid...)
+	}
+
+	return fields, nil
+}
+
+// sortString is the canonical form of the sort order, kept in cursors.
+func sortString(fields []repository.SortField) string {
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = field.Field
+		if field.Desc {
+			parts[i] = "-" + field.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
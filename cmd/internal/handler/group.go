@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/qwaq-dev/test-api/cmd/internal/apperr"
+	"github.com/qwaq-dev/test-api/cmd/internal/repository"
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+	"github.com/qwaq-dev/test-api/pkg/logger/sl"
+)
+
+const (
+	deletePolicyRestrict = "restrict"
+	deletePolicyCascade  = "cascade"
+)
+
+var (
+	errInvalidGroupID = invalidField("invalid_group_id", "id", "Invalid group ID", "Expected a positive integer")
+	errGroupNotFound  = apperr.NotFound("group_not_found", "Group not found")
+	errGroupExists    = apperr.Conflict("group_exists", "Group with this name already exists")
+	errGroupName      = apperr.Validation("invalid_group", "Invalid group", apperr.FieldError{
+		Field:   "name",
+		Code:    "required",
+		Message: "Group name is required",
+	})
+)
+
+// groupID parses the group ID of the path.
+func groupID(c *fiber.Ctx) (int, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id < 1 {
+		return 0, errInvalidGroupID
+	}
+	return id, nil
+}
+
+// groupError reports a missing group as not found and any other failure as
+// an internal error.
+func groupError(detail string, err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return errGroupNotFound.Wrap(err)
+	}
+	return internalError(detail, err)
+}
+
+// @Summary      Добавление новой группы
+// @Description  Создаёт группу с уникальным названием.
+// @Tags         Groups
+// @Accept       json
+// @Produce      json
+// @Param        group  body      structure.Group    true  "Данные группы (название)"
+// @Success      201    {object}  map[string]interface{}  "Группа успешно добавлена"
+// @Failure      400    {object}  apperr.Problem     "Некорректные данные"
+// @Failure      409    {object}  apperr.Problem     "Группа с таким названием уже существует"
+// @Failure      500    {object}  apperr.Problem     "Ошибка сервера"
+// @Router       /api/groups [post]
+func (h *Handler) CreateGroup(c *fiber.Ctx) error {
+	group := new(structure.Group)
+	if err := c.BodyParser(group); err != nil {
+		return errInvalidBody.Wrap(err)
+	}
+
+	group.ID = 0
+	group.Name = strings.TrimSpace(group.Name)
+	if group.Name == "" {
+		return errGroupName
+	}
+
+	if err := h.groups.Create(c.UserContext(), group); err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return errGroupExists.Wrap(err)
+		}
+		return internalError("Error creating group", err)
+	}
+
+	h.logger(c).Info("New group created", slog.String("group", group.Name))
+	return c.Status(201).JSON(fiber.Map{"message": "New group created", "group": group})
+}
+
+// @Summary      Получение списка групп
+// @Description  Возвращает список групп с возможностью поиска по названию и пагинацией.
+// @Tags         Groups
+// @Accept       json
+// @Produce      json
+// @Param        name   query     string  false  "Фильтр по названию группы (поиск по подстроке)"
+// @Param        page   query     int     false  "Номер страницы"  default(1)
+// @Param        limit  query     int     false  "Количество записей на странице"  default(10)
+// @Success      200    {object}  map[string]interface{}  "Список групп"
+// @Failure      500    {object}  apperr.Problem     "Ошибка сервера"
+// @Router       /api/groups [get]
+func (h *Handler) AllGroups(c *fiber.Ctx) error {
+	name := c.Query("name")
+
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+
+	groups, err := h.groups.List(c.UserContext(), repository.GroupFilter{
+		Name:   name,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return internalError("Failed to get groups", err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"page":   page,
+		"limit":  limit,
+		"groups": groups,
+	})
+}
+
+// @Summary      Получение информации о группе
+// @Description  Возвращает группу по её ID вместе со списком её песен.
+// @Tags         Groups
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int                true  "ID группы"
+// @Success      200  {object}  map[string]interface{}  "Группа и её песни"
+// @Failure      400  {object}  apperr.Problem     "Некорректный ID"
+// @Failure      404  {object}  apperr.Problem     "Группа не найдена"
+// @Failure      500  {object}  apperr.Problem     "Ошибка сервера"
+// @Router       /api/group/{id} [get]
+func (h *Handler) GroupById(c *fiber.Ctx) error {
+	id, err := groupID(c)
+	if err != nil {
+		return err
+	}
+
+	group, err := h.groups.GetByID(c.UserContext(), id)
+	if err != nil {
+		return groupError("Failed to get group", err)
+	}
+
+	songs, err := h.groups.Songs(c.UserContext(), id)
+	if err != nil {
+		return internalError("Failed to get group songs", err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{"group": group, "songs": songs})
+}
+
+// @Summary      Переименование группы
+// @Description  Изменяет название группы по её ID.
+// @Tags         Groups
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int              true  "ID группы"
+// @Param        group  body      structure.Group  true  "Новое название группы"
+// @Success      200    {object}  map[string]interface{}  "Группа успешно переименована"
+// @Failure      400    {object}  apperr.Problem     "Некорректный запрос"
+// @Failure      404    {object}  apperr.Problem     "Группа не найдена"
+// @Failure      409    {object}  apperr.Problem     "Группа с таким названием уже существует"
+// @Failure      500    {object}  apperr.Problem     "Ошибка сервера"
+// @Router       /api/group/{id} [put]
+func (h *Handler) RenameGroup(c *fiber.Ctx) error {
+	id, err := groupID(c)
+	if err != nil {
+		return err
+	}
+
+	var body structure.Group
+	if err := c.BodyParser(&body); err != nil {
+		return errInvalidBody.Wrap(err)
+	}
+
+	name := strings.TrimSpace(body.Name)
+	if name == "" {
+		return errGroupName
+	}
+
+	group, err := h.groups.Rename(c.UserContext(), id, name)
+	if err != nil {
+		if errors.Is(err, repository.ErrAlreadyExists) {
+			return errGroupExists.Wrap(err)
+		}
+		return groupError("Error renaming group", err)
+	}
+
+	h.logger(c).Info("Group renamed successfully", sl.ID("group", id), slog.String("name", name))
+	return c.Status(200).JSON(fiber.Map{"message": "Group renamed successfully", "group": group})
+}
+
+// @Summary      Удаление группы по ID
+// @Description  Удаляет группу. По умолчанию (policy=restrict) группу с песнями удалить нельзя; policy=cascade безвозвратно удаляет группу вместе со всеми её песнями, включая песни в корзине: они не попадают в корзину и не могут быть восстановлены.
+// @Tags         Groups
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int     true   "ID группы"
+// @Param        policy  query     string  false  "Что делать с песнями группы"  Enums(restrict, cascade)  default(restrict)
+// @Success      200     {object}  map[string]string  "Группа успешно удалена"
+// @Failure      400     {object}  apperr.Problem     "Некорректный запрос"
+// @Failure      404     {object}  apperr.Problem     "Группа не найдена"
+// @Failure      409     {object}  apperr.Problem     "У группы есть песни"
+// @Failure      500     {object}  apperr.Problem     "Ошибка сервера"
+// @Router       /api/group/{id} [delete]
+func (h *Handler) DeleteGroup(c *fiber.Ctx) error {
+	id, err := groupID(c)
+	if err != nil {
+		return err
+	}
+
+	policy := c.Query("policy", deletePolicyRestrict)
+	if policy != deletePolicyRestrict && policy != deletePolicyCascade {
+		return invalidField("invalid_delete_policy", "policy", "Unknown delete policy", "Expected restrict or cascade")
+	}
+
+	songsCount, err := h.groups.Delete(c.UserContext(), id, policy == deletePolicyCascade)
+	if err != nil {
+		if errors.Is(err, repository.ErrGroupHasSongs) {
+			e := apperr.Conflict("group_has_songs", "Group has songs, use policy=cascade to delete them permanently too")
+			return e.With("songs", songsCount).Wrap(err)
+		}
+		return groupError("Error deleting group", err)
+	}
+
+	h.logger(c).Info("Group deleted successfully", sl.ID("group", id), slog.Int64("songs", songsCount))
+	return c.Status(200).JSON(fiber.Map{"message": fmt.Sprintf("Group with id %d was deleted successfully", id)})
+}
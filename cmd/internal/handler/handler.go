@@ -1,212 +1,369 @@
 package handler
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/qwaq-dev/test-api/cmd/internal/apperr"
+	"github.com/qwaq-dev/test-api/cmd/internal/config"
+	"github.com/qwaq-dev/test-api/cmd/internal/enrichment"
+	"github.com/qwaq-dev/test-api/cmd/internal/health"
+	"github.com/qwaq-dev/test-api/cmd/internal/middleware"
 	"github.com/qwaq-dev/test-api/cmd/internal/repository"
+	"github.com/qwaq-dev/test-api/cmd/internal/service"
 	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+	"github.com/qwaq-dev/test-api/pkg/logger/sl"
 )
 
 type Handler struct {
-	log         *slog.Logger
-	externalApi string
+	log            *slog.Logger
+	service        *service.Songs
+	enrichment     *enrichment.Queue
+	health         *health.Checker
+	songs          repository.SongRepository
+	groups         repository.GroupRepository
+	lyrics         repository.LyricsRepository
+	revisions      repository.RevisionRepository
+	trash          repository.TrashRepository
+	trashRetention time.Duration
 }
 
-func NewHandler(log *slog.Logger, externalApi string) *Handler {
-	return &Handler{log: log, externalApi: externalApi}
+func NewHandler(log *slog.Logger, cfg *config.Config, repo *repository.Repository, songs *service.Songs, queue *enrichment.Queue, checker *health.Checker) *Handler {
+	return &Handler{
+		log:            log,
+		service:        songs,
+		enrichment:     queue,
+		health:         checker,
+		songs:          repo.Songs,
+		groups:         repo.Groups,
+		lyrics:         repo.Lyrics,
+		revisions:      repo.Revisions,
+		trash:          repo.Trash,
+		trashRetention: cfg.Trash.Retention,
+	}
 }
 
-// @Summary      Добавление новой песни
-// @Description  Позволяет добавить песню с указанием названия и группы.
-// @Tags         Songs
-// @Accept       json
-// @Produce      json
-// @Param        song  body      structure.Song  true   "Данные песни (название и группа)"
-// @Success      201   {object}  map[string]interface{}  "Песня успешно добавлена"
-// @Failure      400   {object}  map[string]string  "Некорректные данные"
-// @Failure      500   {object}  map[string]string  "Ошибка сервера"
-// @Router       /api/songs [post]
-func (h *Handler) CreateSong(c *fiber.Ctx) error {
-	song := new(structure.Song)
-	if err := c.BodyParser(song); err != nil {
-		h.log.Error("Failed to parse request body", slog.String("error", err.Error()))
-		return c.Status(400).JSON(fiber.Map{"error": "Failed to parse request body"})
-	}
+// logger returns the logger scoped to the request being handled.
+func (h *Handler) logger(c *fiber.Ctx) *slog.Logger {
+	return middleware.Logger(c, h.log)
+}
 
-	var group structure.Group
-	if err := repository.DB.First(&group, song.GroupID).Error; err != nil {
-		h.log.Error("Group not found", slog.String("error", err.Error()))
-		return c.Status(400).JSON(fiber.Map{"error": "Group not found"})
+// songID parses the song ID of the path.
+func songID(c *fiber.Ctx) (int, error) {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id < 1 {
+		return 0, errInvalidSongID
 	}
+	return id, nil
+}
 
-	externalApiUrl := fmt.Sprintf("%s?group=%s&song=%s", h.externalApi, group.Name, song.Song)
-
-	resp, err := http.Get(externalApiUrl)
-	if err != nil {
-		h.log.Error("Connect to external api failed", slog.String("error", err.Error()))
-		return c.Status(500).JSON(fiber.Map{"error": "Error connecting to external API"})
+// validateSong checks the title and, when requireGroup is set, the group of
+// the song in the request body.
+func validateSong(song *structure.Song, requireGroup bool) error {
+	var fields []apperr.FieldError
+	if strings.TrimSpace(song.Song) == "" {
+		fields = append(fields, apperr.FieldError{Field: "song", Code: "required", Message: "Song title is required"})
+	}
+	if requireGroup && song.GroupID < 1 {
+		fields = append(fields, apperr.FieldError{Field: "group_id", Code: "required", Message: "Expected the ID of an existing group"})
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		h.log.Error("External API returned an error", slog.Any("status", resp.StatusCode))
-		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("External API returned status code: %d", resp.StatusCode)})
+	if len(fields) > 0 {
+		return apperr.Validation("invalid_song", "Invalid song", fields...)
 	}
+	return nil
+}
 
-	body, err := io.ReadAll(resp.Body)
+// songText returns the text of the song, an error when the song has none.
+func (h *Handler) songText(c *fiber.Ctx, id int) (string, error) {
+	text, err := h.songs.Text(c.UserContext(), id)
 	if err != nil {
-		h.log.Error("Failed to read API response", slog.String("error", err.Error()))
-		return c.Status(500).JSON(fiber.Map{"error": "Error reading API response"})
+		return "", songError("Error getting song text", err)
 	}
 
-	songDetails := new(structure.SongDetails)
-	if err := json.Unmarshal(body, &songDetails); err != nil {
-		h.log.Error("Error decoding API response", slog.String("error", err.Error()))
-		return c.Status(500).JSON(fiber.Map{"error": "Error decoding API response"})
+	if strings.TrimSpace(text) == "" {
+		return "", errNoSongText
 	}
 
-	tx := repository.DB.Begin()
+	return text, nil
+}
 
-	if err := tx.Create(&song).Error; err != nil {
-		tx.Rollback()
-		h.log.Error("Error inserting song", slog.String("error", err.Error()))
-		return c.Status(500).JSON(fiber.Map{"error": "Error inserting song"})
+// @Summary      Добавление новой песни
+// @Description  Позволяет добавить песню с указанием названия и группы.
+// @Tags         Songs
+// @Accept       json
+// @Produce      json
+// @Param        song    body      structure.Song  true   "Данные песни (название и группа)"
+// @Param        X-User  header    string          false  "Автор изменения"
+// @Success      200   {object}  map[string]interface{}  "Песня успешно добавлена"
+// @Success      202   {object}  map[string]interface{}  "Песня добавлена, детали будут получены позже"
+// @Failure      400   {object}  apperr.Problem     "Некорректные данные"
+// @Failure      422   {object}  apperr.Problem     "Песня не найдена во внешнем API"
+// @Failure      500   {object}  apperr.Problem     "Ошибка сервера"
+// @Failure      502   {object}  apperr.Problem     "Внешний API вернул ошибку"
+// @Failure      503   {object}  apperr.Problem     "Внешний API временно недоступен"
+// @Failure      504   {object}  apperr.Problem     "Внешний API не ответил вовремя"
+// @Router       /api/songs [post]
+func (h *Handler) CreateSong(c *fiber.Ctx) error {
+	song := new(structure.Song)
+	if err := c.BodyParser(song); err != nil {
+		return bodyError(err)
 	}
 
-	songDetails.SongID = uint(song.ID)
+	if err := validateSong(song, true); err != nil {
+		return err
+	}
 
-	if err := tx.Create(&songDetails).Error; err != nil {
-		tx.Rollback()
-		h.log.Error("Error inserting SongDetails", slog.String("error", err.Error()))
-		return c.Status(500).JSON(fiber.Map{"error": "Error inserting song details"})
+	job, err := h.service.Create(c.UserContext(), song, author(c))
+	if err != nil {
+		return internalError("Error inserting song", err)
 	}
 
-	tx.Commit()
+	if job != nil {
+		h.enrichmentLocation(c, song.ID)
+		h.logger(c).Info("New song created, details are pending", slog.String("song", song.Song))
+		return c.Status(202).JSON(fiber.Map{
+			"message":    "New song created, details are pending enrichment",
+			"song":       song,
+			"enrichment": job,
+		})
+	}
 
-	h.log.Info("New song created", slog.String("song", song.Song))
+	h.logger(c).Info("New song created", slog.String("song", song.Song))
 	return c.Status(200).JSON(fiber.Map{"message": "New song created", "song": song})
 }
 
+// enrichmentLocation points the client to the enrichment status of the song.
+func (h *Handler) enrichmentLocation(c *fiber.Ctx, songID int) {
+	c.Location(fmt.Sprintf("/api/song/%d/enrichment", songID))
+}
+
 // @Summary      Получение списка песен
-// @Description  Возвращает список песен с возможностью фильтрации по названию и группе, а также с пагинацией.
+// @Description  Возвращает список песен с возможностью фильтрации по названию и группе, а также с пагинацией. Если передан параметр cursor (в том числе пустой), используется пагинация по курсору: ответ содержит next_cursor и prev_cursor, а page игнорируется.
 // @Tags         Songs
 // @Accept       json
 // @Produce      json
-// @Param        song   query     string  false  "Фильтр по названию песни (поиск по подстроке)"
-// @Param        group  query     string  false  "Фильтр по названию группы (поиск по подстроке)"
-// @Param        page   query     int     false  "Номер страницы"  default(1)
-// @Param        limit  query     int     false  "Количество записей на странице"  default(10)
+// @Param        song             query     string  false  "Фильтр по названию песни (поиск по подстроке)"
+// @Param        group            query     string  false  "Фильтр по названию группы (поиск по подстроке)"
+// @Param        group_id         query     int     false  "Фильтр по ID группы"
+// @Param        released_after   query     string  false  "Дата выпуска не раньше (YYYY-MM-DD)"  format(date)
+// @Param        released_before  query     string  false  "Дата выпуска не позже (YYYY-MM-DD)"  format(date)
+// @Param        has_text         query     bool    false  "Есть ли у песни текст"
+// @Param        has_link         query     bool    false  "Есть ли у песни ссылка"
+// @Param        sort             query     string  false  "Поля сортировки через запятую, минус перед полем — по убыванию: title, group, release_date (например -release_date,title). По умолчанию новые песни первыми"
+// @Param        page             query     int     false  "Номер страницы"  default(1)
+// @Param        limit            query     int     false  "Количество записей на странице"  default(10)
+// @Param        cursor           query     string  false  "Курсор страницы из next_cursor или prev_cursor, пустой для первой страницы"
+// @Param        with_total       query     bool    false  "Вернуть общее количество песен, подходящих под фильтр"
 // @Success      200    {object}  map[string]interface{}  "Список песен"
-// @Failure      400    {object}  map[string]string  "Некорректный запрос"
-// @Failure      500    {object}  map[string]string  "Ошибка сервера"
+// @Failure      400    {object}  apperr.Problem     "Некорректный запрос"
+// @Failure      500    {object}  apperr.Problem     "Ошибка сервера"
 // @Router       /api/songs [get]
 func (h *Handler) AllSongs(c *fiber.Ctx) error {
-	name := c.Query("song")
-	group := c.Query("group")
+	filter, err := songFilter(c)
+	if err != nil {
+		return err
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	response := fiber.Map{"limit": limit}
+
+	if c.Context().QueryArgs().Has("cursor") {
+		return h.songsByCursor(c, filter, limit, response)
+	}
 
 	page, err := strconv.Atoi(c.Query("page", "1"))
 	if err != nil || page < 1 {
 		page = 1
 	}
 
-	limit, err := strconv.Atoi(c.Query("limit", "10"))
-	if err != nil || limit < 1 {
-		limit = 10
+	filter.Limit = limit
+	filter.Offset = (page - 1) * limit
+
+	songs, err := h.songs.List(c.UserContext(), filter)
+	if err != nil {
+		return internalError("Failed to get songs", err)
 	}
 
-	offset := (page - 1) * limit
+	h.logger(c).Debug("Songs listed", slog.Int("count", len(songs)))
+
+	response["page"] = page
+	response["songs"] = songs
+	return h.songsResponse(c, filter, response)
+}
 
-	var songs []structure.Song
-	query := repository.DB.Model(&structure.Song{}).Preload("Group")
+// songsByCursor serves the keyset paginated listing of AllSongs.
+func (h *Handler) songsByCursor(c *fiber.Ctx, filter repository.SongFilter, limit int, response fiber.Map) error {
+	var cur *cursor
+	if raw := c.Query("cursor"); raw != "" {
+		decoded, err := decodeCursor(raw, filter.Sort)
+		if err != nil {
+			return err
+		}
+		cur = decoded
+	}
 
-	if name != "" {
-		query = query.Where("song ILIKE ?", "%"+name+"%")
+	// One extra song tells whether there is a page beyond this one.
+	filter.Limit = limit + 1
+	filter.Keyset = cur.keyset()
+
+	songs, err := h.songs.List(c.UserContext(), filter)
+	if err != nil {
+		return internalError("Failed to get songs", err)
 	}
 
-	if group != "" {
-		query = query.Joins("JOIN groups ON groups.id = songs.group_id").
-			Where("groups.name ILIKE ?", "%"+group+"%")
+	backward := cur != nil && cur.Backward
+	hasMore := len(songs) > limit
+	if hasMore {
+		if backward {
+			songs = songs[1:]
+		} else {
+			songs = songs[:limit]
+		}
 	}
 
-	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&songs).Error; err != nil {
-		h.log.Error("Failed to get songs", slog.String("error", err.Error()))
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get songs"})
+	var next, prev string
+	if len(songs) > 0 {
+		if hasMore || backward {
+			next, err = h.songCursor(c, filter.Sort, songs[len(songs)-1].ID, false)
+		}
+		if err == nil && ((hasMore && backward) || (cur != nil && !backward)) {
+			prev, err = h.songCursor(c, filter.Sort, songs[0].ID, true)
+		}
+		if err != nil {
+			return internalError("Failed to build songs cursor", err)
+		}
 	}
 
-	h.log.Debug("Song details", slog.Any("songs", songs))
+	h.logger(c).Debug("Songs listed", slog.Int("count", len(songs)))
 
-	return c.Status(200).JSON(fiber.Map{
-		"page":  page,
-		"limit": limit,
-		"songs": songs,
-	})
+	response["songs"] = songs
+	response["next_cursor"] = next
+	response["prev_cursor"] = prev
+	return h.songsResponse(c, filter, response)
+}
+
+// songCursor builds the cursor pointing next to the song in the listing order.
+func (h *Handler) songCursor(c *fiber.Ctx, sort []repository.SortField, id int, backward bool) (string, error) {
+	values, err := h.songs.SortKey(c.UserContext(), id, sort)
+	if err != nil {
+		return "", err
+	}
+
+	return encodeCursor(cursor{ID: id, Sort: sortString(sort), Values: values, Backward: backward}), nil
+}
+
+// songsResponse adds the total count to the listing when with_total is set.
+func (h *Handler) songsResponse(c *fiber.Ctx, filter repository.SongFilter, response fiber.Map) error {
+	if c.QueryBool("with_total") {
+		total, err := h.songs.Count(c.UserContext(), filter)
+		if err != nil {
+			return internalError("Failed to count songs", err)
+		}
+		response["total"] = total
+	}
+
+	return c.Status(200).JSON(response)
 }
 
 // @Summary      Получение текста песни с пагинацией
-// @Description  Возвращает текст песни по ID с возможностью указать номер страницы и количество строк на странице.
+// @Description  Возвращает текст песни по ID с пагинацией по строкам (mode=line) или по куплетам (mode=verse). Куплеты разделены пустыми строками, пустые строки в них не попадают. С параметром at возвращает текущую и следующую строку синхронизированного текста.
 // @Tags         Songs
 // @Accept       json
 // @Produce      json
-// @Param        id    path      int  true  "ID песни"
-// @Param        page  query     int  false  "Номер страницы"  default(1)
-// @Param        limit query     int  false  "Количество строк текста на странице"  default(2)
+// @Param        id    path      int     true   "ID песни"
+// @Param        mode  query     string  false  "Единица пагинации"  Enums(line, verse)  default(line)
+// @Param        page  query     int     false  "Номер страницы"  default(1)
+// @Param        limit query     int     false  "Количество строк (mode=line, по умолчанию 2) или куплетов (mode=verse, по умолчанию 1) на странице"
+// @Param        at    query     int     false  "Время от начала песни в миллисекундах, требует синхронизированный текст"
 // @Success      200   {object}  map[string]interface{}  "Текст песни с пагинацией"
-// @Failure      400   {object}  map[string]string  "Некорректный запрос"
-// @Failure      404   {object}  map[string]string  "Песня или текст не найдены"
-// @Failure      500   {object}  map[string]string  "Ошибка сервера"
+// @Failure      400   {object}  apperr.Problem     "Некорректный запрос"
+// @Failure      404   {object}  apperr.Problem     "Песня или текст не найдены"
+// @Failure      500   {object}  apperr.Problem     "Ошибка сервера"
 // @Router       /api/song/{id}/text [get]
 func (h *Handler) SongText(c *fiber.Ctx) error {
-	id := c.Params("id")
-	var songDetail structure.SongDetails
+	id, err := songID(c)
+	if err != nil {
+		return err
+	}
 
-	if err := repository.DB.Select("text").Where("song_id = ?", id).First(&songDetail).Error; err != nil {
-		h.log.Error("Failed to get text from database", slog.String("error", err.Error()))
-		return c.Status(500).JSON(fiber.Map{"error": "Error getting song text from database"})
+	if c.Query("at") != "" {
+		return h.songTextAt(c, id)
 	}
 
-	if songDetail.Text == "" {
-		h.log.Debug("Song has no text")
-		return c.Status(404).JSON(fiber.Map{"error": "No text available for this song"})
+	mode := c.Query("mode", textModeLine)
+	if mode != textModeLine && mode != textModeVerse {
+		return invalidField("invalid_text_mode", "mode", "Unknown text mode", "Expected line or verse")
 	}
 
-	h.log.Debug("Song text without pagination", slog.Any("text", songDetail.Text))
+	text, err := h.songText(c, id)
+	if err != nil {
+		return err
+	}
 
-	lines := strings.Split(songDetail.Text, "\n")
+	h.logger(c).Debug("Song text loaded", sl.ID("song", id), slog.Int("length", len(text)))
 
 	page, err := strconv.Atoi(c.Query("page", "1"))
 	if err != nil || page < 1 {
 		page = 1
 	}
 
-	limit, err := strconv.Atoi(c.Query("limit", "2"))
+	defaultLimit := 2
+	if mode == textModeVerse {
+		defaultLimit = 1
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit", strconv.Itoa(defaultLimit)))
 	if err != nil || limit < 1 {
-		limit = 2
+		limit = defaultLimit
 	}
 
 	offset := (page - 1) * limit
-	if offset >= len(lines) {
-		h.log.Debug("Page out of range")
-		return c.Status(400).JSON(fiber.Map{"error": "Page out of range"})
+
+	if mode == textModeVerse {
+		verses := textVerses(text)
+		if offset >= len(verses) {
+			return errPageOutOfRange
+		}
+
+		end := min(offset+limit, len(verses))
+		h.logger(c).Debug("Text with pagination", sl.ID("song", id), slog.Int("page", page), slog.Int("verses", end-offset))
+
+		return c.Status(200).JSON(fiber.Map{
+			"mode":         mode,
+			"page":         page,
+			"limit":        limit,
+			"total_pages":  totalPages(len(verses), limit),
+			"total_verses": len(verses),
+			"verses":       verses[offset:end],
+		})
 	}
 
-	end := offset + limit
-	if end > len(lines) {
-		end = len(lines)
+	lines := textLines(text)
+	if offset >= len(lines) {
+		return errPageOutOfRange
 	}
 
-	h.log.Info("Text with pagination", slog.Any("text", lines[offset:end]))
+	end := min(offset+limit, len(lines))
+	h.logger(c).Debug("Text with pagination", sl.ID("song", id), slog.Int("page", page), slog.Int("lines", end-offset))
 
 	return c.Status(200).JSON(fiber.Map{
-		"page":  page,
-		"limit": limit,
-		"text":  lines[offset:end],
+		"mode":        mode,
+		"page":        page,
+		"limit":       limit,
+		"total_pages": totalPages(len(lines), limit),
+		"total_lines": len(lines),
+		"text":        lines[offset:end],
 	})
 }
 
@@ -217,25 +374,22 @@ func (h *Handler) SongText(c *fiber.Ctx) error {
 // @Produce      json
 // @Param        id   path      int                   true  "ID песни"
 // @Success      200  {object}  structure.Song        "Данные о песне"
-// @Failure      400  {object}  map[string]string     "Некорректный запрос (например, ID не является числом)"
-// @Failure      404  {object}  map[string]string     "Песня не найдена"
-// @Failure      500  {object}  map[string]string     "Ошибка сервера"
+// @Failure      400  {object}  apperr.Problem        "Некорректный запрос (например, ID не является числом)"
+// @Failure      404  {object}  apperr.Problem        "Песня не найдена"
+// @Failure      500  {object}  apperr.Problem        "Ошибка сервера"
 // @Router       /api/song/{id} [get]
 func (h *Handler) SongById(c *fiber.Ctx) error {
-	id, err := strconv.Atoi(c.Params("id"))
-	if err != nil || id < 1 {
-		h.log.Error("Invalid song ID", slog.String("id", c.Params("id")))
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid song ID"})
+	id, err := songID(c)
+	if err != nil {
+		return err
 	}
 
-	var song structure.Song
-
-	if err := repository.DB.Preload("Group").Preload("SongDetails").Where("id = ?", id).First(&song).Error; err != nil {
-		h.log.Error("Song not found", slog.String("error", err.Error()))
-		return c.Status(404).JSON(fiber.Map{"error": "Song not found"})
+	song, err := h.songs.GetByID(c.UserContext(), id)
+	if err != nil {
+		return songError("Failed to get song", err)
 	}
 
-	h.log.Info("Song found", slog.Any("song", song))
+	h.logger(c).Debug("Song found", sl.Entity("song", song.ID, song.Song))
 
 	return c.Status(200).JSON(fiber.Map{"song": song})
 }
@@ -245,174 +399,193 @@ func (h *Handler) SongById(c *fiber.Ctx) error {
 // @Tags         Songs
 // @Accept       json
 // @Produce      json
-// @Param        id   path      int                   true  "ID песни"
-// @Param        song body      structure.Song  true  "Объект с обновлёнными данными"
+// @Param        id      path      int             true   "ID песни"
+// @Param        song    body      structure.Song  true   "Объект с обновлёнными данными"
+// @Param        X-User  header    string          false  "Автор изменения"
 // @Success      200  {object}  map[string]string     "Песня успешно обновлена"
-// @Failure      400  {object}  map[string]string     "Некорректный запрос или ошибка валидации"
-// @Failure      404  {object}  map[string]string     "Песня не найдена"
-// @Failure      500  {object}  map[string]string     "Внутренняя ошибка сервера"
+// @Success      202  {object}  map[string]string     "Песня обновлена, детали будут получены позже"
+// @Failure      400  {object}  apperr.Problem        "Некорректный запрос или ошибка валидации"
+// @Failure      404  {object}  apperr.Problem        "Песня не найдена"
+// @Failure      422  {object}  apperr.Problem        "Песня не найдена во внешнем API"
+// @Failure      500  {object}  apperr.Problem        "Внутренняя ошибка сервера"
+// @Failure      502  {object}  apperr.Problem        "Внешний API вернул ошибку"
+// @Failure      503  {object}  apperr.Problem        "Внешний API временно недоступен"
+// @Failure      504  {object}  apperr.Problem        "Внешний API не ответил вовремя"
 // @Router       /api/song/{id} [put]
 func (h *Handler) UpdateSongInfo(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil || id < 1 {
-		h.log.Error("Invalid song ID", slog.String("id", idStr))
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid song ID"})
+	id, err := songID(c)
+	if err != nil {
+		return err
 	}
 
 	var song structure.Song
 	if err := c.BodyParser(&song); err != nil {
-		h.log.Error("Failed to parse request body", slog.String("error", err.Error()))
-		return c.Status(400).JSON(fiber.Map{"error": "Failed to parse body"})
-	}
-
-	var existingSong structure.Song
-	if err := repository.DB.Preload("Group").Where("id = ?", id).First(&existingSong).Error; err != nil {
-		h.log.Error("Song not found", slog.String("error", err.Error()))
-		return c.Status(404).JSON(fiber.Map{"error": "Song not found"})
-	}
-
-	result := repository.DB.Model(&existingSong).Updates(map[string]interface{}{
-		"song": song.Song,
-	})
-
-	if result.Error != nil {
-		h.log.Error("Error updating song", slog.String("error", result.Error.Error()))
-		return c.Status(500).JSON(fiber.Map{"error": "Error updating song"})
-	}
-
-	externalApiUrl := fmt.Sprintf("%s?group=%s&song=%s", h.externalApi, song.Song, existingSong.Group.Name)
-	h.log.Debug("External API URL", slog.String("url", externalApiUrl))
-
-	resp, err := http.Get(externalApiUrl)
-	if err != nil {
-		h.log.Error("Failed to connect to external API", slog.String("error", err.Error()))
-		return c.Status(500).JSON(fiber.Map{"error": "Error connecting to external API"})
+		return bodyError(err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		h.log.Error("External API returned an error", slog.Int("status_code", resp.StatusCode))
-		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("External API returned status code: %d", resp.StatusCode)})
+	if err := validateSong(&song, false); err != nil {
+		return err
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	job, err := h.service.UpdateInfo(c.UserContext(), id, song.Song, author(c))
 	if err != nil {
-		h.log.Error("Failed to read API response", slog.String("error", err.Error()))
-		return c.Status(500).JSON(fiber.Map{"error": "Error reading API response"})
+		return internalError("Error updating song", err)
 	}
 
-	var songDetails structure.SongDetails
-	if err := json.Unmarshal(body, &songDetails); err != nil {
-		h.log.Error("Error decoding API response", slog.String("error", err.Error()))
-		return c.Status(500).JSON(fiber.Map{"error": "Error decoding API response"})
+	if job != nil {
+		h.enrichmentLocation(c, id)
+		h.logger(c).Info("Song updated, details are pending", sl.ID("song", id))
+		return c.Status(202).JSON(fiber.Map{"message": "Song updated, details are pending enrichment", "enrichment": job})
 	}
 
-	songDetails.SongID = uint(id)
-
-	if err := repository.DB.Where("song_id = ?", id).Delete(&structure.SongDetails{}).Error; err != nil {
-		h.log.Error("Error deleting old SongDetails", slog.String("error", err.Error()))
-		return c.Status(500).JSON(fiber.Map{"error": "Error deleting old song details"})
-	}
+	h.logger(c).Info("Song updated successfully", sl.Entity("song", id, song.Song))
+	return c.Status(200).JSON(fiber.Map{"message": "Song updated successfully"})
+}
 
-	if err := repository.DB.Create(&songDetails).Error; err != nil {
-		h.log.Error("Error inserting SongDetails", slog.String("error", err.Error()))
-		return c.Status(500).JSON(fiber.Map{"error": "Error inserting song details"})
+// updatedFields returns the sorted names of the fields changed by a partial
+// update, their values are not worth logging.
+func updatedFields(fields ...map[string]interface{}) []string {
+	var names []string
+	for _, f := range fields {
+		names = slices.AppendSeq(names, maps.Keys(f))
 	}
-
-	h.log.Info("Song updated successfully", slog.Any("song", song))
-	return c.Status(200).JSON(fiber.Map{"message": "Song updated successfully"})
+	slices.Sort(names)
+	return names
 }
 
 // @Summary      Частичное обновление песни
-// @Description  Позволяет обновить одно или несколько полей песни по её ID
+// @Description  Позволяет обновить одно или несколько полей песни по её ID: song, group_id, release_date (YYYY-MM-DD или null), text, link. Другие поля отклоняются.
 // @Tags         Songs
 // @Accept       json
 // @Produce      json
-// @Param        id   path      int                     true  "ID песни"
-// @Param        data body      map[string]interface{}  true  "Данные для обновления (только изменяемые поля)"
+// @Param        id      path      int                     true   "ID песни"
+// @Param        data    body      map[string]interface{}  true   "Данные для обновления (только изменяемые поля)"
+// @Param        X-User  header    string                  false  "Автор изменения"
 // @Success      200  {object}  map[string]string       "Песня успешно обновлена"
-// @Failure      400  {object}  map[string]string       "Некорректный запрос"
-// @Failure      404  {object}  map[string]string       "Песня не найдена"
-// @Failure      500  {object}  map[string]string       "Ошибка сервера"
+// @Failure      400  {object}  apperr.Problem          "Некорректный запрос"
+// @Failure      404  {object}  apperr.Problem          "Песня не найдена"
+// @Failure      500  {object}  apperr.Problem          "Ошибка сервера"
 // @Router       /api/song/{id} [patch]
 func (h *Handler) PartialUpdateSong(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil || id < 1 {
-		h.log.Error("Invalid song ID", slog.String("id", idStr))
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid song ID"})
+	id, err := songID(c)
+	if err != nil {
+		return err
 	}
 
 	var updateData map[string]interface{}
 	if err := c.BodyParser(&updateData); err != nil {
-		h.log.Error("Failed to parse request body", slog.String("error", err.Error()))
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request body"})
+		return errInvalidBody.Wrap(err)
 	}
 
 	if len(updateData) == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No data provided for update"})
+		return apperr.Validation("empty_update", "No data provided for update")
+	}
+
+	songData, detailsData, err := patchFields(updateData)
+	if err != nil {
+		return err
 	}
 
-	var existingSong structure.Song
-	if err := repository.DB.Where("id = ?", id).First(&existingSong).Error; err != nil {
-		h.log.Error("Song not found", slog.String("error", err.Error()))
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Song not found"})
+	if err := h.service.Patch(c.UserContext(), id, songData, detailsData, author(c)); err != nil {
+		return internalError("Update failed", err)
 	}
 
-	if groupID, exists := updateData["group_id"]; exists {
-		var group structure.Group
-		if err := repository.DB.Where("id = ?", groupID).First(&group).Error; err != nil {
-			h.log.Error("Group not found", slog.String("group_id", fmt.Sprintf("%v", groupID)))
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid group ID"})
+	h.logger(c).Info("Song updated successfully", sl.ID("song", id), slog.Any("fields", updatedFields(songData, detailsData)))
+	return c.JSON(fiber.Map{"message": "Song updated"})
+}
+
+// patchFields validates the fields of a partial update and splits them into
+// the columns of the song and of its details. Only the fields clients may
+// edit are accepted, every invalid one is reported.
+func patchFields(data map[string]interface{}) (songFields, detailsFields map[string]interface{}, err error) {
+	songFields = make(map[string]interface{})
+	detailsFields = make(map[string]interface{})
+
+	var invalid []apperr.FieldError
+	reject := func(field, code, message string) {
+		invalid = append(invalid, apperr.FieldError{Field: field, Code: code, Message: message})
+	}
+
+	for _, field := range slices.Sorted(maps.Keys(data)) {
+		value := data[field]
+		switch field {
+		case "song":
+			title, ok := value.(string)
+			switch {
+			case !ok:
+				reject(field, "invalid", "Expected a string")
+			case strings.TrimSpace(title) == "":
+				reject(field, "required", "Song title is required")
+			default:
+				songFields[field] = title
+			}
+		case "group_id":
+			gid, ok := value.(float64)
+			if !ok || gid < 1 || gid != float64(int(gid)) {
+				reject(field, "invalid", "Expected a positive integer")
+				continue
+			}
+			songFields[field] = int(gid)
+		case "release_date":
+			releaseDate, err := releaseDateValue(value)
+			if err != nil {
+				reject(field, "invalid", err.Error())
+				continue
+			}
+			detailsFields[field] = releaseDate
+		case "text", "link":
+			s, ok := value.(string)
+			if !ok {
+				reject(field, "invalid", "Expected a string")
+				continue
+			}
+			detailsFields[field] = s
+		default:
+			reject(field, "unknown_field", "Field can not be updated")
 		}
 	}
 
-	if err := repository.DB.Model(&structure.Song{}).Where("id = ?", id).Updates(updateData).Error; err != nil {
-		h.log.Error("Error updating song", slog.String("error", err.Error()))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Update failed"})
+	if len(invalid) > 0 {
+		return nil, nil, apperr.Validation("invalid_update", "Invalid update", invalid...)
 	}
 
-	h.log.Info("Song updated successfully", slog.Any("song_id", id), slog.Any("updates", updateData))
-	return c.JSON(fiber.Map{"message": "Song updated"})
+	return songFields, detailsFields, nil
+}
+
+// releaseDateValue validates the release_date of a partial update, null
+// clears the date.
+func releaseDateValue(value interface{}) (structure.Date, error) {
+	switch v := value.(type) {
+	case nil:
+		return structure.Date{}, nil
+	case string:
+		return structure.ParseDate(v)
+	}
+	return structure.Date{}, fmt.Errorf("%w, expected a YYYY-MM-DD string", structure.ErrInvalidDate)
 }
 
 // @Summary      Удаление песни по ID
-// @Description  Удаляет песню из базы данных по её уникальному идентификатору.
+// @Description  Перемещает песню в корзину. Её можно восстановить, пока она не удалена окончательно вручную или по истечении срока хранения.
 // @Tags         Songs
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int  true  "ID песни"
-// @Success      200  {object}  map[string]string  "Песня успешно удалена"
-// @Failure      400  {object}  map[string]string  "Некорректный ID"
-// @Failure      404  {object}  map[string]string  "Песня не найдена"
-// @Failure      500  {object}  map[string]string  "Ошибка сервера"
+// @Success      200  {object}  map[string]string  "Песня перемещена в корзину"
+// @Failure      400  {object}  apperr.Problem     "Некорректный ID"
+// @Failure      404  {object}  apperr.Problem     "Песня не найдена"
+// @Failure      500  {object}  apperr.Problem     "Ошибка сервера"
 // @Router       /api/song/{id} [delete]
 func (h *Handler) DeleteSong(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil || id < 1 {
-		h.log.Error("Invalid song ID", slog.String("id", idStr))
-		return c.Status(400).JSON(fiber.Map{"error": "Invalid song ID"})
-	}
-
-	var song structure.Song
-	if err := repository.DB.Where("id = ?", id).First(&song).Error; err != nil {
-		h.log.Error("Song not found", slog.String("error", err.Error()))
-		return c.Status(404).JSON(fiber.Map{"error": "Song not found"})
-	}
-
-	if err := repository.DB.Where("song_id = ?", id).Delete(&structure.SongDetails{}).Error; err != nil {
-		h.log.Error("Error deleting song details", slog.String("error", err.Error()))
-		return c.Status(500).JSON(fiber.Map{"error": "Error deleting song details"})
+	id, err := songID(c)
+	if err != nil {
+		return err
 	}
 
-	if err := repository.DB.Delete(&song).Error; err != nil {
-		h.log.Error("Error deleting song", slog.String("error", err.Error()))
-		return c.Status(500).JSON(fiber.Map{"error": "Error deleting song"})
+	if err := h.service.Delete(c.UserContext(), id); err != nil {
+		return internalError("Error deleting song", err)
 	}
 
-	h.log.Info("Song deleted successfully", slog.Any("song_id", id))
-	return c.Status(200).JSON(fiber.Map{"message": fmt.Sprintf("Song with id %d was deleted successfully", id)})
+	h.logger(c).Info("Song moved to trash", sl.ID("song", id))
+	return c.Status(200).JSON(fiber.Map{"message": fmt.Sprintf("Song with id %d was moved to trash", id)})
 
 }
@@ -0,0 +1,370 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/qwaq-dev/test-api/cmd/internal/apperr"
+	"github.com/qwaq-dev/test-api/cmd/internal/config"
+	"github.com/qwaq-dev/test-api/cmd/internal/enrichment"
+	"github.com/qwaq-dev/test-api/cmd/internal/health"
+	"github.com/qwaq-dev/test-api/cmd/internal/middleware"
+	"github.com/qwaq-dev/test-api/cmd/internal/musicinfo"
+	"github.com/qwaq-dev/test-api/cmd/internal/repository"
+	"github.com/qwaq-dev/test-api/cmd/internal/service"
+)
+
+const testLyrics = "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\n\nYou caught me under false pretenses\nHow long before you let me go?"
+
+// newTestApp serves the song and group routes of the API over the memory
+// repository. The external API knows a single song, Supermassive Black Hole
+// by Muse, and answers 404 for anything else.
+func newTestApp(t *testing.T, async bool) *fiber.App {
+	t.Helper()
+
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("group") != "Muse" || r.URL.Query().Get("song") != "Supermassive Black Hole" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(musicinfo.SongInfo{
+			ReleaseDate: "16.07.2006",
+			Text:        testLyrics,
+			Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+		})
+	}))
+	t.Cleanup(external.Close)
+
+	cfg := &config.Config{
+		ExternalAPI:    external.URL,
+		ExternalClient: config.ExternalClient{Timeout: time.Second},
+		Enrichment:     config.Enrichment{Async: async},
+		Trash:          config.Trash{Retention: 720 * time.Hour},
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo := repository.NewMemoryRepository()
+	songInfo := musicinfo.New(cfg.ExternalAPI, cfg.ExternalClient, nil)
+	queue := enrichment.NewQueue(log, cfg.Enrichment, repo, songInfo)
+	songs := service.NewSongs(log, cfg, repo, songInfo, queue)
+	h := NewHandler(log, cfg, repo, songs, queue, health.NewChecker(time.Second))
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler(log)})
+	app.Use(middleware.RequestID(), middleware.AccessLog(log))
+
+	api := app.Group("/api")
+	api.Get("/songs", h.AllSongs)
+	api.Get("/song/:id", h.SongById)
+	api.Get("/song/:id/text", h.SongText)
+	api.Get("/song/:id/revisions/diff", h.DiffRevisions)
+	api.Post("/songs", h.CreateSong)
+	api.Patch("/song/:id", h.PartialUpdateSong)
+	api.Delete("/song/:id", h.DeleteSong)
+	api.Get("/trash", h.Trash)
+	api.Post("/trash/:id/restore", h.RestoreSong)
+	api.Post("/groups", h.CreateGroup)
+
+	return app
+}
+
+// call sends the request with body encoded as JSON and decodes the JSON
+// response into a map.
+func call(t *testing.T, app *fiber.App, method, path string, body any) (*http.Response, map[string]any) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	var decoded map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil && err != io.EOF {
+		t.Fatalf("%s %s: decode response: %v", method, path, err)
+	}
+
+	return resp, decoded
+}
+
+// expectStatus fails the test unless the response has the given status.
+func expectStatus(t *testing.T, resp *http.Response, body map[string]any, status int) {
+	t.Helper()
+
+	if resp.StatusCode != status {
+		t.Fatalf("%s %s: status %d, want %d, body %v", resp.Request.Method, resp.Request.URL.Path, resp.StatusCode, status, body)
+	}
+}
+
+// expectProblem fails the test unless the response is a problem with the
+// given status and code.
+func expectProblem(t *testing.T, resp *http.Response, body map[string]any, status int, code string) {
+	t.Helper()
+
+	expectStatus(t, resp, body, status)
+	if ct := resp.Header.Get(fiber.HeaderContentType); ct != apperr.ContentType {
+		t.Errorf("content type %q, want %q", ct, apperr.ContentType)
+	}
+	if body["code"] != code {
+		t.Errorf("code %v, want %s", body["code"], code)
+	}
+}
+
+// createSong creates the group Muse with a song and returns the song ID.
+func createSong(t *testing.T, app *fiber.App, title string) int {
+	t.Helper()
+
+	resp, body := call(t, app, fiber.MethodPost, "/api/groups", map[string]any{"name": "Muse"})
+	if resp.StatusCode != fiber.StatusCreated && resp.StatusCode != fiber.StatusOK && resp.StatusCode != fiber.StatusConflict {
+		t.Fatalf("create group: status %d, body %v", resp.StatusCode, body)
+	}
+
+	resp, body = call(t, app, fiber.MethodPost, "/api/songs", map[string]any{"song": title, "group_id": 1})
+	expectStatus(t, resp, body, fiber.StatusOK)
+
+	return int(body["song"].(map[string]any)["id"].(float64))
+}
+
+func TestCreateSong(t *testing.T) {
+	app := newTestApp(t, false)
+	id := createSong(t, app, "Supermassive Black Hole")
+
+	resp, body := call(t, app, fiber.MethodGet, "/api/song/1", nil)
+	expectStatus(t, resp, body, fiber.StatusOK)
+
+	song := body["song"].(map[string]any)
+	details := song["song_details"].(map[string]any)
+	if int(song["id"].(float64)) != id || song["enrichment_status"] != "done" {
+		t.Errorf("song %v, want id %d enriched", song, id)
+	}
+	if details["release_date"] != "2006-07-16" || details["text"] != testLyrics {
+		t.Errorf("details %v, want the ones of the external API", details)
+	}
+}
+
+func TestCreateSongErrors(t *testing.T) {
+	app := newTestApp(t, false)
+	createSong(t, app, "Supermassive Black Hole")
+
+	tests := []struct {
+		name   string
+		body   any
+		status int
+		code   string
+	}{
+		{"no title", map[string]any{"group_id": 1}, fiber.StatusBadRequest, "invalid_song"},
+		{"unknown group", map[string]any{"song": "Uprising", "group_id": 42}, fiber.StatusBadRequest, "unknown_group"},
+		{"unknown song", map[string]any{"song": "Hysteria", "group_id": 1}, fiber.StatusUnprocessableEntity, "external_song_not_found"},
+		{"invalid release date", map[string]any{"song": "Uprising", "group_id": 1, "song_details": map[string]any{"release_date": "someday"}}, fiber.StatusBadRequest, "invalid_release_date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := call(t, app, fiber.MethodPost, "/api/songs", tt.body)
+			expectProblem(t, resp, body, tt.status, tt.code)
+		})
+	}
+}
+
+func TestCreateSongAsync(t *testing.T) {
+	app := newTestApp(t, true)
+
+	call(t, app, fiber.MethodPost, "/api/groups", map[string]any{"name": "Muse"})
+	resp, body := call(t, app, fiber.MethodPost, "/api/songs", map[string]any{"song": "Supermassive Black Hole", "group_id": 1})
+	expectStatus(t, resp, body, fiber.StatusAccepted)
+
+	if location := resp.Header.Get(fiber.HeaderLocation); location != "/api/song/1/enrichment" {
+		t.Errorf("location %q, want the enrichment status of the song", location)
+	}
+	if job := body["enrichment"].(map[string]any); job["status"] != "pending" {
+		t.Errorf("job %v, want pending", job)
+	}
+}
+
+func TestAllSongs(t *testing.T) {
+	app := newTestApp(t, false)
+	// The external API knows only one song, the others are renamed copies.
+	for _, title := range []string{"Uprising", "Starlight"} {
+		id := createSong(t, app, "Supermassive Black Hole")
+		resp, body := call(t, app, fiber.MethodPatch, fmt.Sprintf("/api/song/%d", id), map[string]any{"song": title})
+		expectStatus(t, resp, body, fiber.StatusOK)
+	}
+	createSong(t, app, "Supermassive Black Hole")
+
+	resp, body := call(t, app, fiber.MethodGet, "/api/songs?song=black+hole&limit=1&page=1&with_total=true", nil)
+	expectStatus(t, resp, body, fiber.StatusOK)
+
+	songs := body["songs"].([]any)
+	if len(songs) != 1 || body["total"] != float64(1) {
+		t.Fatalf("songs %v, total %v, want only the latest Supermassive Black Hole", songs, body["total"])
+	}
+
+	resp, body = call(t, app, fiber.MethodGet, "/api/songs?group=mus&limit=2&page=2&with_total=true", nil)
+	expectStatus(t, resp, body, fiber.StatusOK)
+
+	songs = body["songs"].([]any)
+	if len(songs) != 1 || body["total"] != float64(3) {
+		t.Fatalf("songs %v, total %v, want the last of 3 songs", songs, body["total"])
+	}
+}
+
+func TestSongsCursor(t *testing.T) {
+	app := newTestApp(t, false)
+	for range 3 {
+		createSong(t, app, "Supermassive Black Hole")
+	}
+
+	resp, body := call(t, app, fiber.MethodGet, "/api/songs?sort=-release_date&limit=2&cursor=", nil)
+	expectStatus(t, resp, body, fiber.StatusOK)
+
+	next := body["next_cursor"].(string)
+	resp, body = call(t, app, fiber.MethodGet, "/api/songs?sort=-release_date&limit=2&cursor="+next, nil)
+	expectStatus(t, resp, body, fiber.StatusOK)
+	if songs := body["songs"].([]any); len(songs) != 1 || songs[0].(map[string]any)["id"] != float64(1) {
+		t.Fatalf("songs %v, want the oldest song on the second page", songs)
+	}
+
+	sortKey := sortString([]repository.SortField{{Field: repository.SortReleaseDate, Desc: true}})
+	tampered := map[string]string{
+		"release date":    encodeCursor(cursor{ID: 2, Sort: sortKey, Values: []string{"garbage"}}),
+		"no release date": encodeCursor(cursor{ID: 2, Sort: sortKey, Values: []string{""}}),
+		"other sort":      encodeCursor(cursor{ID: 2, Sort: "title", Values: []string{"2006-07-16"}}),
+		"string id":       base64.RawURLEncoding.EncodeToString([]byte(`{"id":"2","s":"` + sortKey + `","v":["2006-07-16"]}`)),
+		"numeric value":   base64.RawURLEncoding.EncodeToString([]byte(`{"id":2,"s":"` + sortKey + `","v":[20060716]}`)),
+		"not base64":      "!!!",
+	}
+	for name, raw := range tampered {
+		t.Run(name, func(t *testing.T) {
+			resp, body := call(t, app, fiber.MethodGet, "/api/songs?sort=-release_date&limit=2&cursor="+raw, nil)
+			expectProblem(t, resp, body, fiber.StatusBadRequest, "invalid_cursor")
+		})
+	}
+}
+
+func TestSongText(t *testing.T) {
+	app := newTestApp(t, false)
+	createSong(t, app, "Supermassive Black Hole")
+
+	resp, body := call(t, app, fiber.MethodGet, "/api/song/1/text?page=2&limit=3", nil)
+	expectStatus(t, resp, body, fiber.StatusOK)
+
+	lines := body["text"].([]any)
+	if len(lines) != 2 || lines[0] != "You caught me under false pretenses" || body["total_pages"] != float64(2) {
+		t.Errorf("page %v, want the last 2 of 5 lines", body)
+	}
+
+	resp, body = call(t, app, fiber.MethodGet, "/api/song/1/text?mode=verse", nil)
+	expectStatus(t, resp, body, fiber.StatusOK)
+
+	if verses := body["verses"].([]any); len(verses) != 1 || body["total_verses"] != float64(2) {
+		t.Errorf("page %v, want the first of 2 verses", body)
+	}
+
+	resp, body = call(t, app, fiber.MethodGet, "/api/song/1/text?page=3&limit=3", nil)
+	expectProblem(t, resp, body, fiber.StatusBadRequest, "page_out_of_range")
+
+	resp, body = call(t, app, fiber.MethodGet, "/api/song/1/text?mode=word", nil)
+	expectProblem(t, resp, body, fiber.StatusBadRequest, "invalid_text_mode")
+}
+
+func TestPartialUpdateSong(t *testing.T) {
+	app := newTestApp(t, false)
+	createSong(t, app, "Supermassive Black Hole")
+
+	resp, body := call(t, app, fiber.MethodPatch, "/api/song/1", map[string]any{
+		"enrichment_status": "failed",
+		"group_id":          1.5,
+		"song":              "",
+	})
+	expectProblem(t, resp, body, fiber.StatusBadRequest, "invalid_update")
+	if errs := body["errors"].([]any); len(errs) != 3 {
+		t.Errorf("errors %v, want one per field", errs)
+	}
+
+	resp, body = call(t, app, fiber.MethodPatch, "/api/song/1", map[string]any{"group_id": 42})
+	expectProblem(t, resp, body, fiber.StatusBadRequest, "unknown_group")
+
+	resp, body = call(t, app, fiber.MethodPatch, "/api/song/2", map[string]any{"song": "Uprising"})
+	expectProblem(t, resp, body, fiber.StatusNotFound, "song_not_found")
+
+	resp, body = call(t, app, fiber.MethodPatch, "/api/song/1", map[string]any{"song": "Uprising", "release_date": nil})
+	expectStatus(t, resp, body, fiber.StatusOK)
+
+	_, body = call(t, app, fiber.MethodGet, "/api/song/1", nil)
+	song := body["song"].(map[string]any)
+	if song["song"] != "Uprising" || song["enrichment_status"] != "done" || song["song_details"].(map[string]any)["release_date"] != nil {
+		t.Errorf("song %v, want renamed without a release date", song)
+	}
+}
+
+func TestDeleteAndRestoreSong(t *testing.T) {
+	app := newTestApp(t, false)
+	createSong(t, app, "Supermassive Black Hole")
+
+	resp, body := call(t, app, fiber.MethodDelete, "/api/song/1", nil)
+	expectStatus(t, resp, body, fiber.StatusOK)
+
+	resp, body = call(t, app, fiber.MethodGet, "/api/song/1", nil)
+	expectProblem(t, resp, body, fiber.StatusNotFound, "song_not_found")
+
+	resp, body = call(t, app, fiber.MethodGet, "/api/song/1/text", nil)
+	expectProblem(t, resp, body, fiber.StatusNotFound, "song_not_found")
+
+	resp, body = call(t, app, fiber.MethodGet, "/api/song/1/revisions/diff?from=1", nil)
+	expectProblem(t, resp, body, fiber.StatusNotFound, "song_not_found")
+
+	resp, body = call(t, app, fiber.MethodGet, "/api/trash", nil)
+	expectStatus(t, resp, body, fiber.StatusOK)
+	if trashed := body["songs"].([]any); len(trashed) != 1 || trashed[0].(map[string]any)["purge_at"] == nil {
+		t.Fatalf("trash %v, want the song with its purge time", trashed)
+	}
+
+	resp, body = call(t, app, fiber.MethodPost, "/api/trash/1/restore", nil)
+	expectStatus(t, resp, body, fiber.StatusOK)
+
+	resp, body = call(t, app, fiber.MethodPost, "/api/trash/1/restore", nil)
+	expectProblem(t, resp, body, fiber.StatusNotFound, "song_not_in_trash")
+
+	resp, body = call(t, app, fiber.MethodGet, "/api/song/1/text", nil)
+	expectStatus(t, resp, body, fiber.StatusOK)
+
+	resp, body = call(t, app, fiber.MethodGet, "/api/song/1/revisions/diff?from=1", nil)
+	expectStatus(t, resp, body, fiber.StatusOK)
+}
+
+func TestInvalidRequests(t *testing.T) {
+	app := newTestApp(t, false)
+
+	resp, body := call(t, app, fiber.MethodGet, "/api/song/abc", nil)
+	expectProblem(t, resp, body, fiber.StatusBadRequest, "invalid_song_id")
+
+	resp, body = call(t, app, fiber.MethodGet, "/api/nowhere", nil)
+	expectProblem(t, resp, body, fiber.StatusNotFound, apperr.CodeRouteNotFound)
+	if body["instance"] != "/api/nowhere" || body["request_id"] == "" {
+		t.Errorf("problem %v, want the path and request id", body)
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/qwaq-dev/test-api/cmd/internal/health"
+	"github.com/qwaq-dev/test-api/cmd/internal/version"
+)
+
+// @Summary      Проверка работы процесса
+// @Description  Отвечает 200, пока процесс запущен. Зависимости не проверяются.
+// @Tags         Health
+// @Produce      json
+// @Success      200  {object}  map[string]string  "Процесс работает"
+// @Router       /healthz [get]
+func (h *Handler) Healthz(c *fiber.Ctx) error {
+	return c.Status(200).JSON(fiber.Map{"status": health.StatusUp})
+}
+
+// @Summary      Готовность к приёму запросов
+// @Description  Проверяет базу данных, версию миграций и, если включено, доступность внешнего API. Для каждого компонента возвращает статус и время проверки. Недоступность внешнего API только понижает статус до degraded.
+// @Tags         Health
+// @Produce      json
+// @Success      200  {object}  health.Report  "Сервис готов (up или degraded)"
+// @Failure      503  {object}  health.Report  "Обязательный компонент недоступен"
+// @Router       /readyz [get]
+func (h *Handler) Readyz(c *fiber.Ctx) error {
+	report := h.health.Ready(c.UserContext())
+
+	if report.Status == health.StatusDown {
+		for _, component := range report.Components {
+			if component.Status == health.StatusDown && !component.Optional {
+				h.logger(c).Warn("Component is not ready", slog.String("component", component.Name), slog.String("error", component.Error))
+			}
+		}
+		return c.Status(503).JSON(report)
+	}
+
+	return c.Status(200).JSON(report)
+}
+
+// @Summary      Версия сервиса
+// @Description  Возвращает версию, коммит и время сборки бинарного файла.
+// @Tags         Health
+// @Produce      json
+// @Success      200  {object}  version.Info  "Информация о сборке"
+// @Router       /version [get]
+func (h *Handler) Version(c *fiber.Ctx) error {
+	return c.Status(200).JSON(version.Get())
+}
@@ -0,0 +1,51 @@
+package handler
+
+import "strings"
+
+const (
+	textModeLine  = "line"
+	textModeVerse = "verse"
+)
+
+// textLines splits the song text into lines, accepting \n, \r\n and \r line
+// endings.
+func textLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	return strings.Split(text, "\n")
+}
+
+// textVerses splits the song text into verses separated by blank lines,
+// the verses keep only their non-empty lines.
+func textVerses(text string) [][]string {
+	var verses [][]string
+	var verse []string
+
+	for _, line := range textLines(text) {
+		line = strings.TrimRightFunc(line, isSpace)
+		if strings.TrimSpace(line) != "" {
+			verse = append(verse, line)
+			continue
+		}
+
+		if len(verse) > 0 {
+			verses = append(verses, verse)
+			verse = nil
+		}
+	}
+
+	if len(verse) > 0 {
+		verses = append(verses, verse)
+	}
+
+	return verses
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+// totalPages returns the number of pages of limit items each.
+func totalPages(n, limit int) int {
+	return (n + limit - 1) / limit
+}
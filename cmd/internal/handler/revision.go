@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/qwaq-dev/test-api/cmd/internal/repository"
+	"github.com/qwaq-dev/test-api/cmd/internal/revision"
+	"github.com/qwaq-dev/test-api/cmd/internal/service"
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+	"github.com/qwaq-dev/test-api/pkg/logger/sl"
+)
+
+const (
+	// authorHeader names the author of a change, recorded in the song revisions.
+	authorHeader    = "X-User"
+	anonymousAuthor = "anonymous"
+	maxAuthorLength = 100
+)
+
+type revisionResponse struct {
+	structure.SongRevision
+	Changes []revision.Change `json:"changes"`
+}
+
+// author returns the author of the change. The header value is copied, Fiber
+// reuses the request buffers.
+func author(c *fiber.Ctx) string {
+	name := strings.Clone(strings.TrimSpace(c.Get(authorHeader)))
+	if name == "" {
+		return anonymousAuthor
+	}
+	if len(name) > maxAuthorLength {
+		name = name[:maxAuthorLength]
+	}
+	return name
+}
+
+// @Summary      История изменений песни
+// @Description  Возвращает ревизии песни, начиная с последней: автора, время, действие, состояние песни и изменения относительно предыдущей ревизии.
+// @Tags         Revisions
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "ID песни"
+// @Success      200  {object}  map[string]interface{}  "Ревизии песни"
+// @Failure      400  {object}  apperr.Problem     "Некорректный ID"
+// @Failure      404  {object}  apperr.Problem     "Песня не найдена"
+// @Failure      500  {object}  apperr.Problem     "Ошибка сервера"
+// @Router       /api/song/{id}/revisions [get]
+func (h *Handler) SongRevisions(c *fiber.Ctx) error {
+	id, err := songID(c)
+	if err != nil {
+		return err
+	}
+
+	if _, err := h.songs.GetByID(c.UserContext(), id); err != nil {
+		return songError("Failed to get song", err)
+	}
+
+	revisions, err := h.revisions.List(c.UserContext(), id)
+	if err != nil {
+		return internalError("Failed to get song revisions", err)
+	}
+
+	response := make([]revisionResponse, len(revisions))
+	for i := range revisions {
+		var prev *structure.SongRevision
+		if i > 0 {
+			prev = &revisions[i-1]
+		}
+
+		response[len(revisions)-1-i] = revisionResponse{
+			SongRevision: revisions[i],
+			Changes:      revision.Diff(prev, &revisions[i]),
+		}
+	}
+
+	return c.Status(200).JSON(fiber.Map{"song_id": id, "revisions": response})
+}
+
+// @Summary      Сравнение ревизий песни
+// @Description  Возвращает изменения между двумя ревизиями песни. Для текста дополнительно возвращается построчный diff, если в обеих версиях не больше 2000 строк, иначе выставляется lines_skipped.
+// @Tags         Revisions
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int  true   "ID песни"
+// @Param        from  query     int  true   "Номер исходной ревизии"
+// @Param        to    query     int  false  "Номер конечной ревизии, по умолчанию последняя"
+// @Success      200   {object}  map[string]interface{}  "Изменения между ревизиями"
+// @Failure      400   {object}  apperr.Problem     "Некорректный запрос"
+// @Failure      404   {object}  apperr.Problem     "Песня или ревизия не найдена"
+// @Failure      500   {object}  apperr.Problem     "Ошибка сервера"
+// @Router       /api/song/{id}/revisions/diff [get]
+func (h *Handler) DiffRevisions(c *fiber.Ctx) error {
+	id, err := songID(c)
+	if err != nil {
+		return err
+	}
+
+	fromNo, err := strconv.Atoi(c.Query("from"))
+	if err != nil || fromNo < 1 {
+		return invalidField("invalid_revision", "from", "Invalid from revision", "Expected a positive revision number")
+	}
+
+	if _, err := h.songs.GetByID(c.UserContext(), id); err != nil {
+		return songError("Failed to get song", err)
+	}
+
+	from, err := h.revisions.Get(c.UserContext(), id, fromNo)
+	if err != nil {
+		return revisionError(err)
+	}
+
+	var to *structure.SongRevision
+	if raw := c.Query("to"); raw != "" {
+		toNo, err := strconv.Atoi(raw)
+		if err != nil || toNo < 1 {
+			return invalidField("invalid_revision", "to", "Invalid to revision", "Expected a positive revision number")
+		}
+
+		if to, err = h.revisions.Get(c.UserContext(), id, toNo); err != nil {
+			return revisionError(err)
+		}
+	} else {
+		revisions, err := h.revisions.List(c.UserContext(), id)
+		if err != nil {
+			return revisionError(err)
+		}
+		to = &revisions[len(revisions)-1]
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"song_id": id,
+		"from":    from.Revision,
+		"to":      to.Revision,
+		"changes": revision.Diff(from, to),
+	})
+}
+
+// @Summary      Откат песни к ревизии
+// @Description  Возвращает название, группу и детали песни к состоянию из указанной ревизии. Откат записывается как новая ревизия.
+// @Tags         Revisions
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int     true   "ID песни"
+// @Param        rev     path      int     true   "Номер ревизии"
+// @Param        X-User  header    string  false  "Автор изменения"
+// @Success      200     {object}  map[string]interface{}  "Песня восстановлена"
+// @Failure      400     {object}  apperr.Problem     "Некорректный запрос"
+// @Failure      404     {object}  apperr.Problem     "Песня или ревизия не найдена"
+// @Failure      409     {object}  apperr.Problem     "Группа из ревизии удалена"
+// @Failure      500     {object}  apperr.Problem     "Ошибка сервера"
+// @Router       /api/song/{id}/revisions/{rev}/restore [post]
+func (h *Handler) RestoreRevision(c *fiber.Ctx) error {
+	id, err := songID(c)
+	if err != nil {
+		return err
+	}
+
+	revNo, err := strconv.Atoi(c.Params("rev"))
+	if err != nil || revNo < 1 {
+		return invalidField("invalid_revision", "rev", "Invalid revision", "Expected a positive revision number")
+	}
+
+	recorded, err := h.service.RestoreRevision(c.UserContext(), id, revNo, author(c))
+	if err != nil {
+		return internalError("Error restoring song", err)
+	}
+
+	h.logger(c).Info("Song restored", sl.ID("song", id), slog.Int("revision", revNo))
+	return c.Status(200).JSON(fiber.Map{
+		"message":  fmt.Sprintf("Song restored to revision %d", revNo),
+		"revision": recorded,
+	})
+}
+
+func revisionError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return service.ErrRevisionNotFound.Wrap(err)
+	}
+	return internalError("Failed to get song revision", err)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/qwaq-dev/test-api/cmd/internal/apperr"
+	"github.com/qwaq-dev/test-api/cmd/internal/repository"
+)
+
+// @Summary      Поиск песен по тексту
+// @Description  Полнотекстовый поиск по текстам песен (русская и английская морфология). Возвращает песни по убыванию релевантности с фрагментами текста в виде HTML: текст экранирован, совпадения выделены тегом <mark>, и номерами совпавших строк.
+// @Tags         Songs
+// @Accept       json
+// @Produce      json
+// @Param        q      query     string  true   "Строка поиска (поддерживает синтаксис websearch: кавычки, OR, -)"
+// @Param        page   query     int     false  "Номер страницы"  default(1)
+// @Param        limit  query     int     false  "Количество записей на странице"  default(10)
+// @Success      200    {object}  map[string]interface{}  "Найденные песни"
+// @Failure      400    {object}  apperr.Problem     "Пустой запрос"
+// @Failure      500    {object}  apperr.Problem     "Ошибка сервера"
+// @Router       /api/search [get]
+func (h *Handler) Search(c *fiber.Ctx) error {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		return apperr.Validation("invalid_query", "Search query is required", apperr.FieldError{
+			Field:   "q",
+			Code:    "required",
+			Message: "Search query is required",
+		})
+	}
+
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	results, err := h.songs.Search(c.UserContext(), repository.SearchFilter{
+		Query:  q,
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	})
+	if err != nil {
+		return internalError("Failed to search songs", err)
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"page":    page,
+		"limit":   limit,
+		"query":   q,
+		"results": results,
+	})
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/qwaq-dev/test-api/cmd/internal/apperr"
+	"github.com/qwaq-dev/test-api/cmd/internal/lrc"
+	"github.com/qwaq-dev/test-api/cmd/internal/repository"
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+	"github.com/qwaq-dev/test-api/pkg/logger/sl"
+)
+
+const (
+	lyricsFormatJSON = "json"
+	lyricsFormatLRC  = "lrc"
+)
+
+var errNoSyncedLyrics = apperr.NotFound("synced_lyrics_not_found", "No synced lyrics for this song")
+
+// @Summary      Загрузка синхронизированного текста песни
+// @Description  Сохраняет текст песни с временными метками в формате LRC, заменяя загруженный ранее. Тело запроса — LRC-файл.
+// @Tags         Lyrics
+// @Accept       plain
+// @Produce      json
+// @Param        id   path      int     true  "ID песни"
+// @Param        lrc  body      string  true  "Текст в формате LRC"
+// @Success      200  {object}  map[string]interface{}  "Текст сохранён"
+// @Failure      400  {object}  apperr.Problem     "Некорректный ID или LRC"
+// @Failure      404  {object}  apperr.Problem     "Песня не найдена"
+// @Failure      500  {object}  apperr.Problem     "Ошибка сервера"
+// @Router       /api/song/{id}/lyrics [put]
+func (h *Handler) UploadLyrics(c *fiber.Ctx) error {
+	id, err := songID(c)
+	if err != nil {
+		return err
+	}
+
+	text := string(c.Body())
+	parsed, err := lrc.Parse(text)
+	if err != nil {
+		return invalidField("invalid_lrc", "body", "Invalid LRC", err.Error())
+	}
+
+	lyrics := structure.SyncedLyrics{SongID: id, LRC: text}
+	if err := h.lyrics.Save(c.UserContext(), &lyrics); err != nil {
+		return songError("Error saving synced lyrics", err)
+	}
+
+	h.logger(c).Info("Synced lyrics saved", sl.ID("song", id), slog.Int("lines", len(parsed.Lines)))
+	return c.Status(200).JSON(fiber.Map{
+		"message": "Synced lyrics saved",
+		"lyrics":  lyricsResponse(&lyrics, parsed),
+	})
+}
+
+// @Summary      Получение синхронизированного текста песни
+// @Description  Возвращает текст песни с временными метками: исходный LRC (format=lrc) или строки с временем начала в миллисекундах (format=json).
+// @Tags         Lyrics
+// @Accept       json
+// @Produce      json,plain
+// @Param        id      path      int     true   "ID песни"
+// @Param        format  query     string  false  "Формат ответа"  Enums(json, lrc)  default(json)
+// @Success      200     {object}  map[string]interface{}  "Синхронизированный текст"
+// @Failure      400     {object}  apperr.Problem     "Некорректный запрос"
+// @Failure      404     {object}  apperr.Problem     "Синхронизированный текст не найден"
+// @Failure      500     {object}  apperr.Problem     "Ошибка сервера"
+// @Router       /api/song/{id}/lyrics [get]
+func (h *Handler) SongLyrics(c *fiber.Ctx) error {
+	id, err := songID(c)
+	if err != nil {
+		return err
+	}
+
+	format := c.Query("format", lyricsFormatJSON)
+	if format != lyricsFormatJSON && format != lyricsFormatLRC {
+		return invalidField("invalid_lyrics_format", "format", "Unknown lyrics format", "Expected json or lrc")
+	}
+
+	lyrics, parsed, err := h.syncedLyrics(c, id)
+	if err != nil {
+		return err
+	}
+
+	if format == lyricsFormatLRC {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+		return c.Status(200).SendString(lyrics.LRC)
+	}
+
+	return c.Status(200).JSON(lyricsResponse(lyrics, parsed))
+}
+
+// @Summary      Удаление синхронизированного текста песни
+// @Tags         Lyrics
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "ID песни"
+// @Success      200  {object}  map[string]string  "Текст удалён"
+// @Failure      400  {object}  apperr.Problem     "Некорректный ID"
+// @Failure      404  {object}  apperr.Problem     "Синхронизированный текст не найден"
+// @Failure      500  {object}  apperr.Problem     "Ошибка сервера"
+// @Router       /api/song/{id}/lyrics [delete]
+func (h *Handler) DeleteLyrics(c *fiber.Ctx) error {
+	id, err := songID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.lyrics.Delete(c.UserContext(), id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return errNoSyncedLyrics.Wrap(err)
+		}
+		return internalError("Error deleting synced lyrics", err)
+	}
+
+	h.logger(c).Info("Synced lyrics deleted", sl.ID("song", id))
+	return c.Status(200).JSON(fiber.Map{"message": "Synced lyrics deleted"})
+}
+
+// songTextAt writes the synced lyrics line shown at the at query parameter
+// and the line after it.
+func (h *Handler) songTextAt(c *fiber.Ctx, id int) error {
+	at, err := strconv.ParseInt(c.Query("at"), 10, 64)
+	if err != nil || at < 0 {
+		return invalidField("invalid_query", "at", "Invalid at", "Expected milliseconds from the song start")
+	}
+
+	_, parsed, err := h.syncedLyrics(c, id)
+	if err != nil {
+		return err
+	}
+
+	current, next := parsed.At(at)
+	return c.Status(200).JSON(fiber.Map{
+		"song_id": id,
+		"at":      at,
+		"current": current,
+		"next":    next,
+	})
+}
+
+// syncedLyrics loads and parses the synced lyrics of the song.
+func (h *Handler) syncedLyrics(c *fiber.Ctx, id int) (*structure.SyncedLyrics, *lrc.Lyrics, error) {
+	lyrics, err := h.lyrics.Get(c.UserContext(), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, errNoSyncedLyrics.Wrap(err)
+		}
+		return nil, nil, internalError("Failed to get synced lyrics", err)
+	}
+
+	parsed, err := lrc.Parse(lyrics.LRC)
+	if err != nil {
+		return nil, nil, apperr.Internal("invalid_stored_lyrics", "Failed to get synced lyrics", err)
+	}
+
+	return lyrics, parsed, nil
+}
+
+func lyricsResponse(lyrics *structure.SyncedLyrics, parsed *lrc.Lyrics) fiber.Map {
+	return fiber.Map{
+		"song_id":    lyrics.SongID,
+		"updated_at": lyrics.UpdatedAt,
+		"tags":       parsed.Tags,
+		"lines":      parsed.Lines,
+	}
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/qwaq-dev/test-api/cmd/internal/apperr"
+	"github.com/qwaq-dev/test-api/cmd/internal/repository"
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+	"github.com/qwaq-dev/test-api/pkg/logger/sl"
+)
+
+var errNotInTrash = apperr.NotFound("song_not_in_trash", "Song not found in trash")
+
+type trashedSong struct {
+	structure.Song
+	// PurgeAt is when the song is purged automatically, nil when the
+	// retention is disabled.
+	PurgeAt *time.Time `json:"purge_at"`
+}
+
+// @Summary      Корзина
+// @Description  Возвращает удалённые песни, начиная с последних удалённых, и время их автоматического окончательного удаления.
+// @Tags         Trash
+// @Accept       json
+// @Produce      json
+// @Param        page   query     int  false  "Номер страницы"  default(1)
+// @Param        limit  query     int  false  "Количество записей на странице"  default(10)
+// @Success      200    {object}  map[string]interface{}  "Удалённые песни"
+// @Failure      500    {object}  apperr.Problem     "Ошибка сервера"
+// @Router       /api/trash [get]
+func (h *Handler) Trash(c *fiber.Ctx) error {
+	page, err := strconv.Atoi(c.Query("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	songs, err := h.trash.List(c.UserContext(), repository.TrashFilter{
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	})
+	if err != nil {
+		return internalError("Failed to get trash", err)
+	}
+
+	trashed := make([]trashedSong, len(songs))
+	for i, song := range songs {
+		trashed[i].Song = song
+		if h.trashRetention > 0 {
+			purgeAt := song.DeletedAt.Time.Add(h.trashRetention)
+			trashed[i].PurgeAt = &purgeAt
+		}
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"page":  page,
+		"limit": limit,
+		"songs": trashed,
+	})
+}
+
+// @Summary      Восстановление песни из корзины
+// @Description  Если детали песни не были получены, она снова ставится в очередь обогащения.
+// @Tags         Trash
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "ID песни"
+// @Success      200  {object}  map[string]string  "Песня восстановлена"
+// @Failure      400  {object}  apperr.Problem     "Некорректный ID"
+// @Failure      404  {object}  apperr.Problem     "Песни нет в корзине"
+// @Failure      500  {object}  apperr.Problem     "Ошибка сервера"
+// @Router       /api/trash/{id}/restore [post]
+func (h *Handler) RestoreSong(c *fiber.Ctx) error {
+	id, err := songID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.Restore(c.UserContext(), id); err != nil {
+		return trashError("Error restoring song", err)
+	}
+
+	h.logger(c).Info("Song restored from trash", sl.ID("song", id))
+	return c.Status(200).JSON(fiber.Map{"message": fmt.Sprintf("Song with id %d was restored", id)})
+}
+
+// @Summary      Окончательное удаление песни из корзины
+// @Description  Удаляет песню из корзины вместе с деталями, синхронизированным текстом и историей изменений. Действие необратимо.
+// @Tags         Trash
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "ID песни"
+// @Success      200  {object}  map[string]string  "Песня удалена окончательно"
+// @Failure      400  {object}  apperr.Problem     "Некорректный ID"
+// @Failure      404  {object}  apperr.Problem     "Песни нет в корзине"
+// @Failure      500  {object}  apperr.Problem     "Ошибка сервера"
+// @Router       /api/trash/{id} [delete]
+func (h *Handler) PurgeSong(c *fiber.Ctx) error {
+	id, err := songID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.trash.Purge(c.UserContext(), id); err != nil {
+		return trashError("Error purging song", err)
+	}
+
+	h.logger(c).Info("Song purged from trash", sl.ID("song", id))
+	return c.Status(200).JSON(fiber.Map{"message": fmt.Sprintf("Song with id %d was deleted permanently", id)})
+}
+
+// trashError reports a song missing from the trash as not found and any other
+// failure as an internal error.
+func trashError(detail string, err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return errNotInTrash.Wrap(err)
+	}
+	return internalError(detail, err)
+}
@@ -0,0 +1,138 @@
+// Package health runs the readiness checks of the service components.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDown     Status = "down"
+	StatusDegraded Status = "degraded"
+)
+
+// Check probes one component. Probe returns nil when the component is usable.
+type Check struct {
+	Name  string
+	Probe func(ctx context.Context) error
+	// Optional components only degrade the service when they are down, they
+	// never make it unready.
+	Optional bool
+	// TTL caches the result of the probe, 0 probes on every report.
+	TTL time.Duration
+}
+
+// Component is the result of a Check.
+type Component struct {
+	Name      string    `json:"name"`
+	Status    Status    `json:"status"`
+	Optional  bool      `json:"optional,omitempty"`
+	LatencyMS float64   `json:"latency_ms"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+	Cached    bool      `json:"cached,omitempty"`
+}
+
+// Report is the readiness of the service: down if any required component is
+// down, degraded if only optional ones are.
+type Report struct {
+	Status     Status      `json:"status"`
+	Components []Component `json:"components"`
+}
+
+type Checker struct {
+	checks  []Check
+	timeout time.Duration
+
+	mu    sync.Mutex
+	cache map[string]Component
+}
+
+// NewChecker returns a Checker running checks, each bounded by timeout when
+// it is positive.
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{
+		checks:  checks,
+		timeout: timeout,
+		cache:   make(map[string]Component),
+	}
+}
+
+// Ready runs all the checks concurrently and returns their results in the
+// order the checks were given.
+func (c *Checker) Ready(ctx context.Context) Report {
+	report := Report{
+		Status:     StatusUp,
+		Components: make([]Component, len(c.checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Components[i] = c.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	for _, component := range report.Components {
+		if component.Status == StatusUp {
+			continue
+		}
+
+		if !component.Optional {
+			report.Status = StatusDown
+			break
+		}
+		report.Status = StatusDegraded
+	}
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) Component {
+	if check.TTL > 0 {
+		c.mu.Lock()
+		cached, ok := c.cache[check.Name]
+		c.mu.Unlock()
+
+		if ok && time.Since(cached.CheckedAt) < check.TTL {
+			cached.Cached = true
+			return cached
+		}
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := check.Probe(ctx)
+
+	component := Component{
+		Name:      check.Name,
+		Status:    StatusUp,
+		Optional:  check.Optional,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		CheckedAt: start,
+	}
+	if err != nil {
+		component.Status = StatusDown
+		component.Error = err.Error()
+	}
+
+	if check.TTL > 0 {
+		c.mu.Lock()
+		c.cache[check.Name] = component
+		c.mu.Unlock()
+	}
+
+	return component
+}
@@ -0,0 +1,156 @@
+// Package lrc parses time-synced lyrics in the LRC format:
+//
+//	[ar:Muse]
+//	[offset:+250]
+//	[00:12.00]Ooh baby, don't you know I suffer?
+//	[00:17.20][01:02.10]Ooh baby, can you hear me moan?
+package lrc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrNoLines = errors.New("lrc: no timed lines")
+
+var (
+	timestampRe = regexp.MustCompile(`^(\d{1,3}):(\d{1,2})(?:[.:](\d{1,3}))?$`)
+	tagRe       = regexp.MustCompile(`^([A-Za-z#]+):(.*)$`)
+)
+
+// SyntaxError reports an invalid line of the LRC text, Line is 1-based.
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("lrc: line %d: %s", e.Line, e.Msg)
+}
+
+// Line is a lyrics line shown from StartMS milliseconds into the song.
+type Line struct {
+	StartMS int64  `json:"start_ms"`
+	Text    string `json:"text"`
+}
+
+// Lyrics are the parsed LRC text. Lines are ordered by start time with the
+// offset tag already applied, a line with several timestamps is repeated.
+type Lyrics struct {
+	Tags  map[string]string `json:"tags,omitempty"`
+	Lines []Line            `json:"lines"`
+}
+
+// Parse parses and validates the LRC text. Lines without a timestamp or a tag
+// are rejected, blank lines are skipped.
+func Parse(text string) (*Lyrics, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	lyrics := &Lyrics{Tags: make(map[string]string)}
+
+	for i, raw := range strings.Split(text, "\n") {
+		lineNo := i + 1
+		raw = strings.TrimSpace(strings.TrimPrefix(raw, "\ufeff"))
+		if raw == "" {
+			continue
+		}
+
+		var starts []int64
+		for strings.HasPrefix(raw, "[") {
+			end := strings.IndexByte(raw, ']')
+			if end < 0 {
+				return nil, &SyntaxError{Line: lineNo, Msg: "unclosed ["}
+			}
+
+			field := raw[1:end]
+			raw = raw[end+1:]
+
+			if m := timestampRe.FindStringSubmatch(field); m != nil {
+				start, err := timestamp(m)
+				if err != nil {
+					return nil, &SyntaxError{Line: lineNo, Msg: err.Error()}
+				}
+				starts = append(starts, start)
+				continue
+			}
+
+			m := tagRe.FindStringSubmatch(field)
+			if m == nil || len(starts) > 0 {
+				return nil, &SyntaxError{Line: lineNo, Msg: fmt.Sprintf("invalid timestamp [%s], expected [mm:ss.xx]", field)}
+			}
+			lyrics.Tags[strings.ToLower(m[1])] = strings.TrimSpace(m[2])
+		}
+
+		if len(starts) == 0 {
+			if strings.TrimSpace(raw) != "" {
+				return nil, &SyntaxError{Line: lineNo, Msg: "line has no [mm:ss.xx] timestamp"}
+			}
+			continue
+		}
+
+		for _, start := range starts {
+			lyrics.Lines = append(lyrics.Lines, Line{StartMS: start, Text: strings.TrimSpace(raw)})
+		}
+	}
+
+	if len(lyrics.Lines) == 0 {
+		return nil, ErrNoLines
+	}
+
+	if offset, ok := lyrics.Tags["offset"]; ok {
+		ms, err := strconv.ParseInt(offset, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("lrc: invalid offset %q", offset)
+		}
+
+		// A positive offset shows the lines earlier.
+		for i := range lyrics.Lines {
+			lyrics.Lines[i].StartMS = max(lyrics.Lines[i].StartMS-ms, 0)
+		}
+	}
+
+	sort.SliceStable(lyrics.Lines, func(i, j int) bool {
+		return lyrics.Lines[i].StartMS < lyrics.Lines[j].StartMS
+	})
+
+	return lyrics, nil
+}
+
+// timestamp converts the submatches of timestampRe to milliseconds.
+func timestamp(m []string) (int64, error) {
+	minutes, _ := strconv.ParseInt(m[1], 10, 64)
+	seconds, _ := strconv.ParseInt(m[2], 10, 64)
+	if seconds >= 60 {
+		return 0, fmt.Errorf("invalid seconds in %s:%s", m[1], m[2])
+	}
+
+	// The fraction is in hundredths in most files, but tenths and
+	// thousandths are used too.
+	var ms int64
+	if frac := m[3]; frac != "" {
+		ms, _ = strconv.ParseInt(frac, 10, 64)
+		for i := len(frac); i < 3; i++ {
+			ms *= 10
+		}
+	}
+
+	return (minutes*60+seconds)*1000 + ms, nil
+}
+
+// At returns the line shown at ms milliseconds into the song and the line
+// that follows it, either is nil before the first or after the last line.
+func (l *Lyrics) At(ms int64) (current, next *Line) {
+	i := sort.Search(len(l.Lines), func(i int) bool { return l.Lines[i].StartMS > ms })
+	if i > 0 {
+		current = &l.Lines[i-1]
+	}
+	if i < len(l.Lines) {
+		next = &l.Lines[i]
+	}
+	return current, next
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+const startedAtKey = "metrics:started_at"
+
+// InstrumentGORM measures the duration of every query run through db and
+// exports the statistics of its connection pool.
+func (m *Metrics) InstrumentGORM(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	if err := m.registry.Register(collectors.NewDBStatsCollector(sqlDB, db.Name())); err != nil {
+		return err
+	}
+
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("*").Register("metrics:before_create", m.before),
+		cb.Create().After("*").Register("metrics:after_create", m.after("create")),
+		cb.Query().Before("*").Register("metrics:before_query", m.before),
+		cb.Query().After("*").Register("metrics:after_query", m.after("query")),
+		cb.Update().Before("*").Register("metrics:before_update", m.before),
+		cb.Update().After("*").Register("metrics:after_update", m.after("update")),
+		cb.Delete().Before("*").Register("metrics:before_delete", m.before),
+		cb.Delete().After("*").Register("metrics:after_delete", m.after("delete")),
+		cb.Row().Before("*").Register("metrics:before_row", m.before),
+		cb.Row().After("*").Register("metrics:after_row", m.after("row")),
+		cb.Raw().Before("*").Register("metrics:before_raw", m.before),
+		cb.Raw().After("*").Register("metrics:after_raw", m.after("raw")),
+	)
+}
+
+func (m *Metrics) before(db *gorm.DB) {
+	db.InstanceSet(startedAtKey, time.Now())
+}
+
+func (m *Metrics) after(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startedAtKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		result := "ok"
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			result = "error"
+		}
+
+		m.dbDuration.WithLabelValues(operation, db.Statement.Table, result).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/qwaq-dev/test-api/cmd/internal/apperr"
+	"github.com/qwaq-dev/test-api/pkg/logger/sl"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	loggerKey = "logger"
+	errorKey  = "error"
+)
+
+// AccessLog stores in the context a child of log carrying the request id,
+// method, path, remote address and trace id, then logs one line per request
+// with its route, status, duration and size. An error of the request is
+// rendered by the app ErrorHandler before the line is logged and is not
+// returned, the middlewares running before AccessLog get it from
+// RequestError. It must run after RequestID.
+func AccessLog(log *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		attrs := []any{
+			slog.String("request_id", GetRequestID(c)),
+			slog.String("method", strings.Clone(c.Method())),
+			slog.String("path", strings.Clone(c.Path())),
+			slog.String("remote_addr", c.IP()),
+		}
+		if span := trace.SpanContextFromContext(c.UserContext()); span.HasTraceID() {
+			attrs = append(attrs, slog.String("trace_id", span.TraceID().String()))
+		}
+
+		reqLog := log.With(attrs...)
+		c.Locals(loggerKey, reqLog)
+
+		err := c.Next()
+
+		route := c.Route().Path
+		if err != nil {
+			if apperr.From(err).Code == apperr.CodeRouteNotFound {
+				// No route matched, c.Route is the last middleware.
+				route = ""
+			}
+
+			c.Locals(errorKey, err)
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+
+		level := slog.LevelInfo
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= fiber.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		reqLog.Log(c.UserContext(), level, "Request handled",
+			slog.String("route", route),
+			slog.Int("status", status),
+			sl.Duration("duration", time.Since(start)),
+			slog.Int("bytes", len(c.Response().Body())),
+		)
+
+		return nil
+	}
+}
+
+// RequestError returns err or, when it is nil, the error of the request that
+// AccessLog already handled.
+func RequestError(c *fiber.Ctx, err error) error {
+	if err != nil {
+		return err
+	}
+
+	err, _ = c.Locals(errorKey).(error)
+	return err
+}
+
+// Logger returns the request logger stored by AccessLog with the route of the
+// running handler added, or fallback outside of AccessLog.
+func Logger(c *fiber.Ctx, fallback *slog.Logger) *slog.Logger {
+	log, ok := c.Locals(loggerKey).(*slog.Logger)
+	if !ok {
+		return fallback
+	}
+
+	return log.With(slog.String("route", c.Route().Path))
+}
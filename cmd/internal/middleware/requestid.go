@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	HeaderRequestID = "X-Request-ID"
+
+	requestIDKey    = "request_id"
+	maxRequestIDLen = 128
+)
+
+// RequestID reuses the X-Request-ID header of the request, or generates one
+// when it is missing or malformed, and echoes it in the response.
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(HeaderRequestID)
+		if !validRequestID(id) {
+			id = newRequestID()
+		} else {
+			id = strings.Clone(id)
+		}
+
+		c.Locals(requestIDKey, id)
+		c.Set(HeaderRequestID, id)
+
+		return c.Next()
+	}
+}
+
+// GetRequestID returns the id assigned by RequestID, empty outside of it.
+func GetRequestID(c *fiber.Ctx) string {
+	id, _ := c.Locals(requestIDKey).(string)
+	return id
+}
+
+// validRequestID accepts printable ASCII ids short enough to be logged.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
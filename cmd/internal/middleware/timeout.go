@@ -0,0 +1,48 @@
+// Package middleware holds the fiber middlewares shared by all routes.
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/qwaq-dev/test-api/cmd/internal/apperr"
+)
+
+// Timeout gives every request a context that is cancelled after timeout, when
+// the handler returns or when base is cancelled, so the database queries and
+// external API calls started with c.UserContext() do not outlive the request
+// or the server. A request that ran out of time fails with a timeout error
+// whatever the handler responded. A zero timeout leaves the requests without
+// a deadline.
+//
+// A client disconnect does not cancel the context: fasthttp reads the whole
+// request before calling the handler and does not watch the connection
+// afterwards, so the deadline is what bounds the work of an abandoned request.
+func Timeout(base context.Context, timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var (
+			ctx    context.Context
+			cancel context.CancelFunc
+		)
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(c.UserContext(), timeout)
+		} else {
+			ctx, cancel = context.WithCancel(c.UserContext())
+		}
+		defer cancel()
+
+		stop := context.AfterFunc(base, cancel)
+		defer stop()
+
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return apperr.Timeout(apperr.CodeRequestTimeout, "Request timed out", ctx.Err())
+		}
+
+		return err
+	}
+}
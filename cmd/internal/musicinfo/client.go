@@ -0,0 +1,317 @@
+package musicinfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/config"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	infoPath        = "/info"
+	maxResponseSize = 1 << 20
+	tracerName      = "github.com/qwaq-dev/test-api/cmd/internal/musicinfo"
+)
+
+var ErrNotFound = errors.New("song not found in external API")
+
+// StatusError is returned when the external API answers with an unexpected status code.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("external API returned status code: %d", e.StatusCode)
+}
+
+// DecodeError is returned when the external API response can not be decoded.
+type DecodeError struct {
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("error decoding external API response: %s", e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// SongInfo is the response of the external /info endpoint.
+type SongInfo struct {
+	ReleaseDate string `json:"release_date"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// Lookup outcomes reported to the Observer.
+const (
+	OutcomeOK          = "ok"
+	OutcomeNotFound    = "not_found"
+	OutcomeStatusError = "status_error"
+	OutcomeDecodeError = "decode_error"
+	OutcomeCircuitOpen = "circuit_open"
+	OutcomeTimeout     = "timeout"
+	OutcomeCanceled    = "canceled"
+	OutcomeError       = "error"
+)
+
+// Observer is told the outcome and duration of every Info call, retries
+// included.
+type Observer interface {
+	ObserveLookup(outcome string, duration time.Duration)
+}
+
+// Client queries the external song info API.
+type Client struct {
+	observer    Observer
+	baseURL     string
+	http        *http.Client
+	breaker     *breaker
+	timeout     time.Duration
+	maxRetries  int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+}
+
+// New returns a Client for the API at baseURL. observer may be nil.
+func New(baseURL string, cfg config.ExternalClient, observer Observer) *Client {
+	return &Client{
+		observer:    observer,
+		baseURL:     baseURL,
+		http:        &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)},
+		breaker:     newBreaker(cfg.BreakerThreshold, cfg.BreakerTimeout),
+		timeout:     cfg.Timeout,
+		maxRetries:  cfg.MaxRetries,
+		backoffBase: cfg.BackoffBase,
+		backoffMax:  cfg.BackoffMax,
+	}
+}
+
+// Info returns the details of the song from the /info endpoint. Network errors
+// and 5xx responses are retried up to maxRetries times with jittered
+// exponential backoff. While the circuit breaker is open ErrCircuitOpen is
+// returned without calling the API.
+func (c *Client) Info(ctx context.Context, group, song string) (info *SongInfo, err error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "musicinfo.Info", trace.WithAttributes(
+		attribute.String("song.group", group),
+		attribute.String("song.name", song),
+	))
+	defer func() {
+		outcome := Outcome(err)
+		span.SetAttributes(attribute.String("musicinfo.outcome", outcome))
+		if err != nil && outcome != OutcomeNotFound {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	if c.observer != nil {
+		start := time.Now()
+		defer func() {
+			c.observer.ObserveLookup(Outcome(err), time.Since(start))
+		}()
+	}
+
+	if !c.breaker.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	info, err = c.info(ctx, group, song)
+	switch {
+	case err == nil || !retryable(err):
+		// The API answered, so it is up even if the answer is an error.
+		c.breaker.success()
+	case errors.Is(ctx.Err(), context.Canceled):
+		// The caller gave up, this says nothing about the API.
+		c.breaker.release()
+	default:
+		c.breaker.failure()
+	}
+
+	return info, err
+}
+
+// Ping checks that the external API can be reached. Any answer below 500
+// counts, the circuit breaker is neither consulted nor updated.
+func (c *Client) Ping(ctx context.Context) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	return nil
+}
+
+// BreakerState returns the state of the circuit breaker: closed, open or half-open.
+func (c *Client) BreakerState() string {
+	return c.breaker.current().String()
+}
+
+// Outcome classifies the error returned by Info.
+func Outcome(err error) string {
+	var statusErr *StatusError
+	var decodeErr *DecodeError
+
+	switch {
+	case err == nil:
+		return OutcomeOK
+	case errors.Is(err, ErrNotFound):
+		return OutcomeNotFound
+	case errors.Is(err, ErrCircuitOpen):
+		return OutcomeCircuitOpen
+	case errors.As(err, &statusErr):
+		return OutcomeStatusError
+	case errors.As(err, &decodeErr):
+		return OutcomeDecodeError
+	case errors.Is(err, context.DeadlineExceeded):
+		return OutcomeTimeout
+	case errors.Is(err, context.Canceled):
+		return OutcomeCanceled
+	}
+	return OutcomeError
+}
+
+// Unavailable reports whether err means the external API can not be reached
+// at the moment, as opposed to it answering with an error for this song.
+func Unavailable(err error) bool {
+	return errors.Is(err, ErrCircuitOpen) || retryable(err)
+}
+
+func (c *Client) info(ctx context.Context, group, song string) (*SongInfo, error) {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid external API url: %w", err)
+	}
+	u = u.JoinPath(infoPath)
+
+	query := u.Query()
+	query.Set("group", group)
+	query.Set("song", song)
+	u.RawQuery = query.Encode()
+
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, c.backoff(attempt)); err != nil {
+				return nil, err
+			}
+		}
+
+		info, err := c.do(ctx, u.String())
+		if err == nil {
+			return info, nil
+		}
+
+		lastErr = err
+		if !retryable(err) || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, lastErr
+}
+
+func (c *Client) do(ctx context.Context, rawURL string) (*SongInfo, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	info := new(SongInfo)
+	if err := json.Unmarshal(body, info); err != nil {
+		return nil, &DecodeError{Err: err}
+	}
+
+	return info, nil
+}
+
+// backoff returns a random delay in [0, min(backoffMax, backoffBase*2^(attempt-1))).
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.backoffBase << (attempt - 1)
+	if delay <= 0 || (c.backoffMax > 0 && delay > c.backoffMax) {
+		delay = c.backoffMax
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(delay)))
+}
+
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+
+	var decodeErr *DecodeError
+	return !errors.Is(err, ErrNotFound) && !errors.As(err, &decodeErr)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
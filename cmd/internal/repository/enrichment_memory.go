@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+)
+
+type EnrichmentMemory struct {
+	store *memoryStore
+}
+
+func (r *EnrichmentMemory) Enqueue(ctx context.Context, songID int) (*structure.EnrichmentJob, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	song, ok := s.songs[songID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	song.EnrichmentStatus = structure.EnrichmentPending
+	s.songs[songID] = song
+
+	for _, job := range s.jobs {
+		if job.SongID == songID && job.Status == structure.JobPending {
+			return &job, nil
+		}
+	}
+
+	now := time.Now()
+	s.nextJobID++
+	job := structure.EnrichmentJob{
+		ID:        s.nextJobID,
+		SongID:    songID,
+		Status:    structure.JobPending,
+		RunAt:     now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.jobs[job.ID] = job
+
+	return &job, nil
+}
+
+func (r *EnrichmentMemory) Claim(ctx context.Context, limit int, lease time.Duration) ([]structure.EnrichmentJob, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	due := make([]structure.EnrichmentJob, 0)
+	for _, job := range s.jobs {
+		if (job.Status == structure.JobPending || job.Status == structure.JobRunning) && !job.RunAt.After(now) {
+			due = append(due, job)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool { return due[i].RunAt.Before(due[j].RunAt) })
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+
+	for i := range due {
+		due[i].Status = structure.JobRunning
+		due[i].Attempts++
+		due[i].RunAt = now.Add(lease)
+		due[i].UpdatedAt = now
+		s.jobs[due[i].ID] = due[i]
+	}
+
+	return due, nil
+}
+
+func (r *EnrichmentMemory) Complete(ctx context.Context, job *structure.EnrichmentJob, details *structure.SongDetails) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.jobs[job.ID]
+	if !ok || stored.Status != structure.JobRunning || stored.Attempts != job.Attempts {
+		return ErrLeaseLost
+	}
+
+	song, ok := s.songs[job.SongID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	song.EnrichmentStatus = structure.EnrichmentDone
+	s.songs[job.SongID] = song
+	s.setDetails(job.SongID, details)
+
+	return r.update(job, structure.JobDone, job.RunAt, "")
+}
+
+func (r *EnrichmentMemory) Retry(ctx context.Context, job *structure.EnrichmentJob, runAt time.Time, reason string) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return r.update(job, structure.JobPending, runAt, reason)
+}
+
+func (r *EnrichmentMemory) Dead(ctx context.Context, job *structure.EnrichmentJob, reason string) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if song, ok := s.songs[job.SongID]; ok {
+		song.EnrichmentStatus = structure.EnrichmentFailed
+		s.songs[job.SongID] = song
+	}
+
+	return r.update(job, structure.JobDead, job.RunAt, reason)
+}
+
+func (r *EnrichmentMemory) Latest(ctx context.Context, songID int) (*structure.EnrichmentJob, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var latest *structure.EnrichmentJob
+	for _, job := range s.jobs {
+		if job.SongID == songID && (latest == nil || job.ID > latest.ID) {
+			job := job
+			latest = &job
+		}
+	}
+
+	if latest == nil {
+		return nil, ErrNotFound
+	}
+
+	return latest, nil
+}
+
+// update changes the stored job and its copy. The caller must hold the lock.
+func (r *EnrichmentMemory) update(job *structure.EnrichmentJob, status string, runAt time.Time, reason string) error {
+	stored, ok := r.store.jobs[job.ID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	stored.Status = status
+	stored.RunAt = runAt
+	stored.LastError = reason
+	stored.UpdatedAt = time.Now()
+	r.store.jobs[job.ID] = stored
+
+	*job = stored
+	return nil
+}
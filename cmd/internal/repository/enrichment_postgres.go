@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EnrichmentPostgres struct {
+	db *gorm.DB
+}
+
+func (r *EnrichmentPostgres) Enqueue(ctx context.Context, songID int) (*structure.EnrichmentJob, error) {
+	var job structure.EnrichmentJob
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&structure.Song{}).Where("id = ?", songID).
+			Update("enrichment_status", structure.EnrichmentPending)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		err := tx.Where("song_id = ? AND status = ?", songID, structure.JobPending).
+			Order("id DESC").First(&job).Error
+		if err == nil {
+			return nil
+		}
+
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		job = structure.EnrichmentJob{
+			SongID: songID,
+			Status: structure.JobPending,
+			RunAt:  time.Now(),
+		}
+		return tx.Create(&job).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (r *EnrichmentPostgres) Claim(ctx context.Context, limit int, lease time.Duration) ([]structure.EnrichmentJob, error) {
+	var jobs []structure.EnrichmentJob
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND run_at <= ?", []string{structure.JobPending, structure.JobRunning}, now).
+			Order("run_at").Limit(limit).Find(&jobs).Error
+		if err != nil || len(jobs) == 0 {
+			return err
+		}
+
+		ids := make([]int, len(jobs))
+		for i := range jobs {
+			ids[i] = jobs[i].ID
+			jobs[i].Status = structure.JobRunning
+			jobs[i].Attempts++
+			jobs[i].RunAt = now.Add(lease)
+		}
+
+		return tx.Model(&structure.EnrichmentJob{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":   structure.JobRunning,
+			"attempts": gorm.Expr("attempts + 1"),
+			"run_at":   now.Add(lease),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+func (r *EnrichmentPostgres) Complete(ctx context.Context, job *structure.EnrichmentJob, details *structure.SongDetails) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Every claim counts an attempt, so the count tells whether the job
+		// was claimed again since.
+		result := tx.Model(job).Where("status = ? AND attempts = ?", structure.JobRunning, job.Attempts).
+			Updates(map[string]interface{}{
+				"status":     structure.JobDone,
+				"last_error": "",
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrLeaseLost
+		}
+
+		if err := tx.Where("song_id = ?", job.SongID).Delete(&structure.SongDetails{}).Error; err != nil {
+			return err
+		}
+
+		details.ID = 0
+		details.SongID = uint(job.SongID)
+		if err := tx.Create(details).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&structure.Song{}).Where("id = ?", job.SongID).
+			Update("enrichment_status", structure.EnrichmentDone).Error
+	})
+}
+
+func (r *EnrichmentPostgres) Retry(ctx context.Context, job *structure.EnrichmentJob, runAt time.Time, reason string) error {
+	return r.db.WithContext(ctx).Model(job).Updates(map[string]interface{}{
+		"status":     structure.JobPending,
+		"run_at":     runAt,
+		"last_error": reason,
+	}).Error
+}
+
+func (r *EnrichmentPostgres) Dead(ctx context.Context, job *structure.EnrichmentJob, reason string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&structure.Song{}).Where("id = ?", job.SongID).
+			Update("enrichment_status", structure.EnrichmentFailed).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(job).Updates(map[string]interface{}{
+			"status":     structure.JobDead,
+			"last_error": reason,
+		}).Error
+	})
+}
+
+func (r *EnrichmentPostgres) Latest(ctx context.Context, songID int) (*structure.EnrichmentJob, error) {
+	var job structure.EnrichmentJob
+	if err := r.db.WithContext(ctx).Where("song_id = ?", songID).Order("id DESC").First(&job).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	return &job, nil
+}
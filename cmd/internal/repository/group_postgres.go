@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+	"gorm.io/gorm"
+)
+
+type GroupPostgres struct {
+	db *gorm.DB
+}
+
+func (r *GroupPostgres) Create(ctx context.Context, group *structure.Group) error {
+	return translateError(r.db.WithContext(ctx).Create(group).Error)
+}
+
+func (r *GroupPostgres) List(ctx context.Context, filter GroupFilter) ([]structure.Group, error) {
+	var groups []structure.Group
+	query := r.db.WithContext(ctx).Model(&structure.Group{})
+
+	if filter.Name != "" {
+		query = query.Where("name ILIKE ?", "%"+filter.Name+"%")
+	}
+
+	if err := query.Order("name ASC").Limit(filter.Limit).Offset(filter.Offset).Find(&groups).Error; err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+func (r *GroupPostgres) GetByID(ctx context.Context, id int) (*structure.Group, error) {
+	var group structure.Group
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&group).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	return &group, nil
+}
+
+func (r *GroupPostgres) Songs(ctx context.Context, id int) ([]structure.Song, error) {
+	var songs []structure.Song
+	err := r.db.WithContext(ctx).Preload("SongDetails").Where("group_id = ?", id).Order("id DESC").Find(&songs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return songs, nil
+}
+
+func (r *GroupPostgres) Rename(ctx context.Context, id int, name string) (*structure.Group, error) {
+	group, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.db.WithContext(ctx).Model(group).Update("name", name).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	return group, nil
+}
+
+func (r *GroupPostgres) Delete(ctx context.Context, id int, cascade bool) (int64, error) {
+	var songsCount int64
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).Limit(1).Find(&structure.Group{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		// Deleted songs still belong to the group until they are purged.
+		if err := tx.Unscoped().Model(&structure.Song{}).Where("group_id = ?", id).Count(&songsCount).Error; err != nil {
+			return err
+		}
+
+		if songsCount > 0 && !cascade {
+			return ErrGroupHasSongs
+		}
+
+		songIDs := tx.Unscoped().Model(&structure.Song{}).Select("id").Where("group_id = ?", id)
+		if err := tx.Where("song_id IN (?)", songIDs).Delete(&structure.SongDetails{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("group_id = ?", id).Delete(&structure.Song{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("id = ?", id).Delete(&structure.Group{}).Error
+	})
+
+	return songsCount, err
+}
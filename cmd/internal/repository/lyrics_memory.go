@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+)
+
+type LyricsMemory struct {
+	store *memoryStore
+}
+
+func (r *LyricsMemory) Get(ctx context.Context, songID int) (*structure.SyncedLyrics, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Songs in the trash keep their lyrics until purged but do not show them.
+	if _, ok := s.songs[songID]; !ok {
+		return nil, ErrNotFound
+	}
+
+	lyrics, ok := s.lyrics[songID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &lyrics, nil
+}
+
+func (r *LyricsMemory) Save(ctx context.Context, lyrics *structure.SyncedLyrics) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.songs[lyrics.SongID]; !ok {
+		return ErrNotFound
+	}
+
+	lyrics.UpdatedAt = time.Now()
+	s.lyrics[lyrics.SongID] = *lyrics
+	return nil
+}
+
+func (r *LyricsMemory) Delete(ctx context.Context, songID int) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.songs[songID]; !ok {
+		return ErrNotFound
+	}
+	if _, ok := s.lyrics[songID]; !ok {
+		return ErrNotFound
+	}
+
+	delete(s.lyrics, songID)
+	return nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LyricsPostgres struct {
+	db *gorm.DB
+}
+
+func (r *LyricsPostgres) Get(ctx context.Context, songID int) (*structure.SyncedLyrics, error) {
+	var lyrics structure.SyncedLyrics
+	err := r.db.WithContext(ctx).
+		Joins("JOIN songs ON songs.id = synced_lyrics.song_id AND songs.deleted_at IS NULL").
+		First(&lyrics, "synced_lyrics.song_id = ?", songID).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &lyrics, nil
+}
+
+func (r *LyricsPostgres) Save(ctx context.Context, lyrics *structure.SyncedLyrics) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var song structure.Song
+		if err := tx.Select("id").First(&song, lyrics.SongID).Error; err != nil {
+			return translateError(err)
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "song_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"lrc", "updated_at"}),
+		}).Create(lyrics).Error
+	})
+}
+
+func (r *LyricsPostgres) Delete(ctx context.Context, songID int) error {
+	result := r.db.WithContext(ctx).Delete(&structure.SyncedLyrics{},
+		"song_id = ? AND song_id IN (SELECT id FROM songs WHERE deleted_at IS NULL)", songID)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
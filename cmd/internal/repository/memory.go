@@ -0,0 +1,584 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+	"gorm.io/gorm"
+)
+
+// memoryStore keeps all the data of the in-memory backend. Songs are stored
+// without their Group and SongDetails, these are attached on read. Deleted
+// songs are moved to trash and keep their details until purged.
+type memoryStore struct {
+	mu             sync.RWMutex
+	groups         map[int]structure.Group
+	songs          map[int]structure.Song
+	trash          map[int]structure.Song
+	details        map[int]structure.SongDetails
+	jobs           map[int]structure.EnrichmentJob
+	lyrics         map[int]structure.SyncedLyrics
+	revisions      map[int][]structure.SongRevision
+	nextGroupID    int
+	nextSongID     int
+	nextDetailsID  uint
+	nextJobID      int
+	nextRevisionID int
+}
+
+// NewMemoryRepository returns a Repository that keeps everything in process
+// memory. It is meant for tests and local demos.
+func NewMemoryRepository() *Repository {
+	return newMemoryRepository(&memoryStore{
+		groups:    make(map[int]structure.Group),
+		songs:     make(map[int]structure.Song),
+		trash:     make(map[int]structure.Song),
+		details:   make(map[int]structure.SongDetails),
+		jobs:      make(map[int]structure.EnrichmentJob),
+		lyrics:    make(map[int]structure.SyncedLyrics),
+		revisions: make(map[int][]structure.SongRevision),
+	})
+}
+
+func newMemoryRepository(store *memoryStore) *Repository {
+	return &Repository{
+		Songs:      &SongMemory{store: store},
+		Groups:     &GroupMemory{store: store},
+		Enrichment: &EnrichmentMemory{store: store},
+		Lyrics:     &LyricsMemory{store: store},
+		Revisions:  &RevisionMemory{store: store},
+		Trash:      &TrashMemory{store: store},
+		atomic:     store.atomic,
+	}
+}
+
+// atomic runs fn against a copy of the store and keeps the copy when fn
+// succeeds. The store stays locked meanwhile, so transactions are serialized
+// with any other access.
+func (s *memoryStore) atomic(ctx context.Context, fn func(tx *Repository) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx := s.clone()
+	if err := fn(newMemoryRepository(tx)); err != nil {
+		return err
+	}
+
+	s.groups, s.songs, s.trash, s.details = tx.groups, tx.songs, tx.trash, tx.details
+	s.jobs, s.lyrics, s.revisions = tx.jobs, tx.lyrics, tx.revisions
+	s.nextGroupID, s.nextSongID, s.nextDetailsID = tx.nextGroupID, tx.nextSongID, tx.nextDetailsID
+	s.nextJobID, s.nextRevisionID = tx.nextJobID, tx.nextRevisionID
+	return nil
+}
+
+// clone returns a copy of the data of the store. The caller must hold the lock.
+func (s *memoryStore) clone() *memoryStore {
+	revisions := make(map[int][]structure.SongRevision, len(s.revisions))
+	for id, songRevisions := range s.revisions {
+		revisions[id] = slices.Clone(songRevisions)
+	}
+
+	return &memoryStore{
+		groups:         maps.Clone(s.groups),
+		songs:          maps.Clone(s.songs),
+		trash:          maps.Clone(s.trash),
+		details:        maps.Clone(s.details),
+		jobs:           maps.Clone(s.jobs),
+		lyrics:         maps.Clone(s.lyrics),
+		revisions:      revisions,
+		nextGroupID:    s.nextGroupID,
+		nextSongID:     s.nextSongID,
+		nextDetailsID:  s.nextDetailsID,
+		nextJobID:      s.nextJobID,
+		nextRevisionID: s.nextRevisionID,
+	}
+}
+
+// song returns a copy of the stored song with its group and details attached.
+// The caller must hold the lock.
+func (s *memoryStore) song(id int) structure.Song {
+	song := s.songs[id]
+	song.Group = s.groups[song.GroupID]
+	song.SongDetails = s.details[id]
+	return song
+}
+
+func (s *memoryStore) nameTaken(name string, exceptID int) bool {
+	for _, group := range s.groups {
+		if group.Name == name && group.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+// setDetails replaces the details of the song. The caller must hold the lock.
+func (s *memoryStore) setDetails(id int, details *structure.SongDetails) {
+	s.nextDetailsID++
+	details.ID = s.nextDetailsID
+	details.SongID = uint(id)
+	s.details[id] = *details
+}
+
+// deleteSong removes the song, whether in the trash or not, with everything
+// attached to it.
+func (s *memoryStore) deleteSong(id int) {
+	delete(s.details, id)
+	delete(s.lyrics, id)
+	delete(s.revisions, id)
+	delete(s.songs, id)
+	delete(s.trash, id)
+	for jobID, job := range s.jobs {
+		if job.SongID == id {
+			delete(s.jobs, jobID)
+		}
+	}
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// page cuts the [offset, offset+limit) window out of n elements.
+// A non-positive limit means no limit.
+func page(n, limit, offset int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > n {
+		offset = n
+	}
+
+	end := n
+	if limit > 0 && offset+limit < n {
+		end = offset + limit
+	}
+
+	return offset, end
+}
+
+type SongMemory struct {
+	store *memoryStore
+}
+
+func (r *SongMemory) Create(ctx context.Context, song *structure.Song) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, ok := s.groups[song.GroupID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	s.nextSongID++
+	s.nextDetailsID++
+
+	song.ID = s.nextSongID
+	song.Group = group
+	if song.EnrichmentStatus == "" {
+		song.EnrichmentStatus = structure.EnrichmentDone
+	}
+	song.SongDetails.ID = s.nextDetailsID
+	song.SongDetails.SongID = uint(song.ID)
+
+	stored := *song
+	stored.Group = structure.Group{}
+	stored.SongDetails = structure.SongDetails{}
+
+	s.songs[song.ID] = stored
+	s.details[song.ID] = song.SongDetails
+	return nil
+}
+
+func (r *SongMemory) List(ctx context.Context, filter SongFilter) ([]structure.Song, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	songs := s.filterSongs(filter)
+
+	keys := make(map[int][]string, len(songs))
+	for _, song := range songs {
+		full := s.song(song.ID)
+		keys[song.ID] = songSortKey(&full, filter.Sort)
+	}
+
+	sort.Slice(songs, func(i, j int) bool {
+		a, b := songs[i].ID, songs[j].ID
+		return compareSongKeys(filter.Sort, keys[a], a, keys[b], b) < 0
+	})
+
+	if filter.Keyset == nil {
+		start, end := page(len(songs), filter.Limit, filter.Offset)
+		return songs[start:end], nil
+	}
+
+	// pos is the index of the first song after the keyset one.
+	keyset := filter.Keyset
+	pos := sort.Search(len(songs), func(i int) bool {
+		return compareSongKeys(filter.Sort, keys[songs[i].ID], songs[i].ID, keyset.Values, keyset.ID) > 0
+	})
+
+	if !keyset.Backward {
+		start, end := page(len(songs), filter.Limit, pos)
+		return songs[start:end], nil
+	}
+
+	end := sort.Search(len(songs), func(i int) bool {
+		return compareSongKeys(filter.Sort, keys[songs[i].ID], songs[i].ID, keyset.Values, keyset.ID) >= 0
+	})
+	start := 0
+	if filter.Limit > 0 && end-filter.Limit > 0 {
+		start = end - filter.Limit
+	}
+
+	return songs[start:end], nil
+}
+
+func (r *SongMemory) Count(ctx context.Context, filter SongFilter) (int64, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return int64(len(s.filterSongs(filter))), nil
+}
+
+func (r *SongMemory) SortKey(ctx context.Context, id int, sort []SortField) ([]string, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.songs[id]; !ok {
+		return nil, ErrNotFound
+	}
+
+	song := s.song(id)
+	return songSortKey(&song, sort), nil
+}
+
+// filterSongs returns the songs matching the filter, without pagination.
+// The caller must hold the lock.
+func (s *memoryStore) filterSongs(filter SongFilter) []structure.Song {
+	songs := make([]structure.Song, 0, len(s.songs))
+	for id := range s.songs {
+		song := s.song(id)
+		if !matchSong(&song, filter) {
+			continue
+		}
+
+		// The Postgres implementation only preloads the group in listings.
+		song.SongDetails = structure.SongDetails{}
+		songs = append(songs, song)
+	}
+
+	return songs
+}
+
+func matchSong(song *structure.Song, filter SongFilter) bool {
+	if filter.Song != "" && !containsFold(song.Song, filter.Song) {
+		return false
+	}
+	if filter.Group != "" && !containsFold(song.Group.Name, filter.Group) {
+		return false
+	}
+	if filter.GroupID != 0 && song.GroupID != filter.GroupID {
+		return false
+	}
+
+	if filter.ReleasedAfter != nil || filter.ReleasedBefore != nil {
+		released := song.SongDetails.ReleaseDate
+		if released.IsZero() {
+			return false
+		}
+		if filter.ReleasedAfter != nil && released.Before(*filter.ReleasedAfter) {
+			return false
+		}
+		if filter.ReleasedBefore != nil && released.After(*filter.ReleasedBefore) {
+			return false
+		}
+	}
+
+	if filter.HasText != nil && (song.SongDetails.Text != "") != *filter.HasText {
+		return false
+	}
+	if filter.HasLink != nil && (song.SongDetails.Link != "") != *filter.HasLink {
+		return false
+	}
+
+	return true
+}
+
+func (r *SongMemory) GetByID(ctx context.Context, id int) (*structure.Song, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.songs[id]; !ok {
+		return nil, ErrNotFound
+	}
+
+	song := s.song(id)
+	return &song, nil
+}
+
+func (r *SongMemory) Text(ctx context.Context, id int) (string, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.songs[id]; !ok {
+		return "", ErrNotFound
+	}
+
+	details, ok := s.details[id]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return details.Text, nil
+}
+
+func (r *SongMemory) ReplaceInfo(ctx context.Context, id int, title string, details *structure.SongDetails) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	song, ok := s.songs[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	song.Song = title
+	song.EnrichmentStatus = structure.EnrichmentDone
+	s.songs[id] = song
+
+	s.setDetails(id, details)
+	return nil
+}
+
+func (r *SongMemory) Update(ctx context.Context, id int, fields map[string]interface{}) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	song, ok := s.songs[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	for column, value := range fields {
+		switch column {
+		case "song":
+			title, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("invalid value for column %q: %v", column, value)
+			}
+			song.Song = title
+		case "group_id":
+			var groupID int
+			switch v := value.(type) {
+			case int:
+				groupID = v
+			case float64:
+				groupID = int(v)
+			default:
+				return fmt.Errorf("invalid value for column %q: %v", column, value)
+			}
+			if _, ok := s.groups[groupID]; !ok {
+				return fmt.Errorf("group %d does not exist", groupID)
+			}
+			song.GroupID = groupID
+		case "enrichment_status":
+			status, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("invalid value for column %q: %v", column, value)
+			}
+			song.EnrichmentStatus = status
+		default:
+			return fmt.Errorf("unknown column %q", column)
+		}
+	}
+
+	s.songs[id] = song
+	return nil
+}
+
+func (r *SongMemory) UpdateDetails(ctx context.Context, id int, fields map[string]interface{}) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.songs[id]; !ok {
+		return ErrNotFound
+	}
+
+	details, exists := s.details[id]
+	for column, value := range fields {
+		var ok bool
+		switch column {
+		case "release_date":
+			details.ReleaseDate, ok = value.(structure.Date)
+		case "text":
+			details.Text, ok = value.(string)
+		case "link":
+			details.Link, ok = value.(string)
+		default:
+			return fmt.Errorf("unknown column %q", column)
+		}
+		if !ok {
+			return fmt.Errorf("invalid value for column %q: %v", column, value)
+		}
+	}
+
+	if !exists {
+		s.setDetails(id, &details)
+		return nil
+	}
+
+	s.details[id] = details
+	return nil
+}
+
+func (r *SongMemory) Delete(ctx context.Context, id int) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	song, ok := s.songs[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	song.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	s.trash[id] = song
+	delete(s.songs, id)
+	return nil
+}
+
+type GroupMemory struct {
+	store *memoryStore
+}
+
+func (r *GroupMemory) Create(ctx context.Context, group *structure.Group) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.nameTaken(group.Name, 0) {
+		return ErrAlreadyExists
+	}
+
+	s.nextGroupID++
+	group.ID = s.nextGroupID
+	s.groups[group.ID] = *group
+	return nil
+}
+
+func (r *GroupMemory) List(ctx context.Context, filter GroupFilter) ([]structure.Group, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	groups := make([]structure.Group, 0, len(s.groups))
+	for _, group := range s.groups {
+		if filter.Name != "" && !containsFold(group.Name, filter.Name) {
+			continue
+		}
+		groups = append(groups, group)
+	}
+
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+
+	start, end := page(len(groups), filter.Limit, filter.Offset)
+	return groups[start:end], nil
+}
+
+func (r *GroupMemory) GetByID(ctx context.Context, id int) (*structure.Group, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	group, ok := s.groups[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &group, nil
+}
+
+func (r *GroupMemory) Songs(ctx context.Context, id int) ([]structure.Song, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	songs := make([]structure.Song, 0)
+	for songID, song := range s.songs {
+		if song.GroupID != id {
+			continue
+		}
+
+		song.SongDetails = s.details[songID]
+		songs = append(songs, song)
+	}
+
+	sort.Slice(songs, func(i, j int) bool { return songs[i].ID > songs[j].ID })
+	return songs, nil
+}
+
+func (r *GroupMemory) Rename(ctx context.Context, id int, name string) (*structure.Group, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, ok := s.groups[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	if s.nameTaken(name, id) {
+		return nil, ErrAlreadyExists
+	}
+
+	group.Name = name
+	s.groups[id] = group
+	return &group, nil
+}
+
+func (r *GroupMemory) Delete(ctx context.Context, id int, cascade bool) (int64, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.groups[id]; !ok {
+		return 0, ErrNotFound
+	}
+
+	// Deleted songs still belong to the group until they are purged.
+	var songIDs []int
+	for _, songs := range []map[int]structure.Song{s.songs, s.trash} {
+		for songID, song := range songs {
+			if song.GroupID == id {
+				songIDs = append(songIDs, songID)
+			}
+		}
+	}
+
+	if len(songIDs) > 0 && !cascade {
+		return int64(len(songIDs)), ErrGroupHasSongs
+	}
+
+	for _, songID := range songIDs {
+		s.deleteSong(songID)
+	}
+
+	delete(s.groups, id)
+	return int64(len(songIDs)), nil
+}
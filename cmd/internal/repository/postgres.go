@@ -1,36 +1,73 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 
 	"github.com/qwaq-dev/test-api/cmd/internal/config"
-	"github.com/qwaq-dev/test-api/cmd/internal/structure"
 	"github.com/qwaq-dev/test-api/pkg/logger/sl"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-var DB *gorm.DB
-
-func NewPostgresDB(cfg config.Database, log *slog.Logger) error {
+func NewPostgresDB(cfg config.Database, log *slog.Logger) (*gorm.DB, error) {
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
 		cfg.Host, cfg.Username, cfg.Password, cfg.Name, cfg.Port, cfg.SSLMode)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Error("No database with this settings", sl.Err(err))
-		return err
+		return nil, err
 	}
 
-	DB = db
+	log.Info("Success connect to database")
+	return db, nil
+}
 
-	err = DB.AutoMigrate(&structure.Song{}, &structure.SongDetails{})
-	if err != nil {
-		log.Error("Migration failed", sl.Err(err))
-		return err
+// NewPostgresRepository returns the GORM backed implementation of Repository.
+func NewPostgresRepository(db *gorm.DB) *Repository {
+	atomic := func(ctx context.Context, fn func(tx *Repository) error) error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(NewPostgresRepository(tx))
+		})
 	}
 
-	log.Info("Success connect to database")
-	return nil
+	ping := func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+	close := func() error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	}
+
+	return &Repository{
+		Songs:      &SongPostgres{db: db},
+		Groups:     &GroupPostgres{db: db},
+		Enrichment: &EnrichmentPostgres{db: db},
+		Lyrics:     &LyricsPostgres{db: db},
+		Revisions:  &RevisionPostgres{db: db},
+		Trash:      &TrashPostgres{db: db},
+		atomic:     atomic,
+		ping:       ping,
+		close:      close,
+	}
+}
+
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrAlreadyExists
+	}
+	return err
 }
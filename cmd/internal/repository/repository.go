@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+)
+
+var (
+	ErrNotFound      = errors.New("record not found")
+	ErrAlreadyExists = errors.New("record already exists")
+	ErrGroupHasSongs = errors.New("group has songs")
+	// ErrLeaseLost means the job was claimed again after its lease expired.
+	ErrLeaseLost = errors.New("job lease lost")
+)
+
+// SongFilter describes the filters, sorting and pagination of a song listing.
+// Release dates bounds are inclusive, nil pointers mean no filter. Songs are
+// ordered by Sort and then by ID descending. When Keyset is set Offset is
+// ignored and the page starts next to the Keyset song.
+type SongFilter struct {
+	Song           string
+	Group          string
+	GroupID        int
+	ReleasedAfter  *time.Time
+	ReleasedBefore *time.Time
+	HasText        *bool
+	HasLink        *bool
+	Sort           []SortField
+	Limit          int
+	Offset         int
+	Keyset         *Keyset
+}
+
+// Keyset is the position of a keyset page: the songs listed right after the
+// song with ID and sort key Values, or right before it when Backward is set.
+// Songs are still returned in the listing order.
+type Keyset struct {
+	ID       int
+	Values   []string
+	Backward bool
+}
+
+// SearchFilter describes a full-text search over the lyrics.
+type SearchFilter struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+// TrashFilter describes the pagination of the trash listing.
+type TrashFilter struct {
+	Limit  int
+	Offset int
+}
+
+// GroupFilter describes the name filter and pagination of a group listing.
+type GroupFilter struct {
+	Name   string
+	Limit  int
+	Offset int
+}
+
+type SongRepository interface {
+	// Create stores the song together with its SongDetails.
+	Create(ctx context.Context, song *structure.Song) error
+	List(ctx context.Context, filter SongFilter) ([]structure.Song, error)
+	// Count returns the number of songs matching the filter, ignoring pagination.
+	Count(ctx context.Context, filter SongFilter) (int64, error)
+	// SortKey returns the values of the sort fields of the song, as used in Keyset.
+	SortKey(ctx context.Context, id int, sort []SortField) ([]string, error)
+	// GetByID returns the song with its group and details preloaded.
+	GetByID(ctx context.Context, id int) (*structure.Song, error)
+	Text(ctx context.Context, id int) (string, error)
+	// Search returns the songs whose lyrics match the query, best match first.
+	Search(ctx context.Context, filter SearchFilter) ([]structure.SearchResult, error)
+	// ReplaceInfo renames the song and replaces its SongDetails.
+	ReplaceInfo(ctx context.Context, id int, title string, details *structure.SongDetails) error
+	Update(ctx context.Context, id int, fields map[string]interface{}) error
+	// UpdateDetails updates the columns of the SongDetails of the song,
+	// creating them if the song has none yet.
+	UpdateDetails(ctx context.Context, id int, fields map[string]interface{}) error
+	// Delete moves the song to the trash.
+	Delete(ctx context.Context, id int) error
+}
+
+type GroupRepository interface {
+	Create(ctx context.Context, group *structure.Group) error
+	List(ctx context.Context, filter GroupFilter) ([]structure.Group, error)
+	GetByID(ctx context.Context, id int) (*structure.Group, error)
+	Songs(ctx context.Context, id int) ([]structure.Song, error)
+	Rename(ctx context.Context, id int, name string) (*structure.Group, error)
+	// Delete removes the group. With cascade the group songs, those in the
+	// trash included, are purged too: they skip the trash and can not be
+	// restored. Otherwise ErrGroupHasSongs is returned for a group that still
+	// has songs.
+	Delete(ctx context.Context, id int, cascade bool) (int64, error)
+}
+
+type EnrichmentRepository interface {
+	// Enqueue schedules a lookup of the song details and marks the song as
+	// pending. A job that is already waiting for the song is reused.
+	Enqueue(ctx context.Context, songID int) (*structure.EnrichmentJob, error)
+	// Claim marks up to limit due jobs as running for the lease duration and
+	// returns them.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]structure.EnrichmentJob, error)
+	// Complete stores the fetched details and marks the job and the song done.
+	// ErrLeaseLost is returned when the job is no longer held by this claim.
+	Complete(ctx context.Context, job *structure.EnrichmentJob, details *structure.SongDetails) error
+	// Retry puts the job back to the queue to be run again at runAt.
+	Retry(ctx context.Context, job *structure.EnrichmentJob, runAt time.Time, reason string) error
+	// Dead gives up on the job and marks the song enrichment as failed.
+	Dead(ctx context.Context, job *structure.EnrichmentJob, reason string) error
+	// Latest returns the most recent job of the song.
+	Latest(ctx context.Context, songID int) (*structure.EnrichmentJob, error)
+}
+
+type LyricsRepository interface {
+	// Get returns the synced lyrics of the song.
+	Get(ctx context.Context, songID int) (*structure.SyncedLyrics, error)
+	// Save stores the synced lyrics of the song, replacing the previous ones.
+	Save(ctx context.Context, lyrics *structure.SyncedLyrics) error
+	Delete(ctx context.Context, songID int) error
+}
+
+type RevisionRepository interface {
+	// Record stores the current state of the song and its details as a new
+	// revision. Nothing is stored when the state matches the latest revision,
+	// which is returned instead.
+	Record(ctx context.Context, songID int, action, author string) (*structure.SongRevision, error)
+	// List returns the revisions of the song, oldest first.
+	List(ctx context.Context, songID int) ([]structure.SongRevision, error)
+	Get(ctx context.Context, songID, revision int) (*structure.SongRevision, error)
+}
+
+// TrashRepository manages the deleted songs. They are kept with their
+// details, lyrics and revisions until purged.
+type TrashRepository interface {
+	// List returns the deleted songs, most recently deleted first.
+	List(ctx context.Context, filter TrashFilter) ([]structure.Song, error)
+	// Restore brings the deleted song back.
+	Restore(ctx context.Context, id int) error
+	// Purge removes the deleted song permanently.
+	Purge(ctx context.Context, id int) error
+	// PurgeBefore removes permanently the songs deleted before the given time
+	// and returns their number.
+	PurgeBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Repository bundles the storage used by the handlers.
+type Repository struct {
+	Songs      SongRepository
+	Groups     GroupRepository
+	Enrichment EnrichmentRepository
+	Lyrics     LyricsRepository
+	Revisions  RevisionRepository
+	Trash      TrashRepository
+
+	atomic func(ctx context.Context, fn func(tx *Repository) error) error
+	ping   func(ctx context.Context) error
+	close  func() error
+}
+
+// Atomic runs fn in a transaction. The changes made through the Repository
+// passed to fn are kept only if fn returns nil, otherwise they are rolled back
+// and the error of fn is returned.
+func (r *Repository) Atomic(ctx context.Context, fn func(tx *Repository) error) error {
+	return r.atomic(ctx, fn)
+}
+
+// Intercept returns a copy of r with its repositories, and those of its
+// transactions, passed through wrap. It lets tests make single operations of
+// a transaction fail.
+func (r *Repository) Intercept(wrap func(repo *Repository)) *Repository {
+	intercepted := *r
+	wrap(&intercepted)
+
+	intercepted.atomic = func(ctx context.Context, fn func(tx *Repository) error) error {
+		return r.atomic(ctx, func(tx *Repository) error {
+			return fn(tx.Intercept(wrap))
+		})
+	}
+
+	return &intercepted
+}
+
+// Ping checks that the storage can be reached.
+func (r *Repository) Ping(ctx context.Context) error {
+	if r.ping == nil {
+		return nil
+	}
+	return r.ping(ctx)
+}
+
+// Close releases the connections held by the repository. The Repository must
+// not be used afterwards.
+func (r *Repository) Close() error {
+	if r.close == nil {
+		return nil
+	}
+	return r.close()
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+)
+
+type RevisionMemory struct {
+	store *memoryStore
+}
+
+func (r *RevisionMemory) Record(ctx context.Context, songID int, action, author string) (*structure.SongRevision, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.songs[songID]; !ok {
+		return nil, ErrNotFound
+	}
+
+	song := s.song(songID)
+	recorded := structure.NewSongRevision(&song, action, author)
+
+	revisions := s.revisions[songID]
+	if n := len(revisions); n > 0 && revisions[n-1].SameContent(&recorded) {
+		latest := revisions[n-1]
+		return &latest, nil
+	}
+
+	s.nextRevisionID++
+	recorded.ID = s.nextRevisionID
+	recorded.Revision = len(revisions) + 1
+	recorded.CreatedAt = time.Now()
+	s.revisions[songID] = append(revisions, recorded)
+
+	return &recorded, nil
+}
+
+func (r *RevisionMemory) List(ctx context.Context, songID int) ([]structure.SongRevision, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]structure.SongRevision(nil), s.revisions[songID]...), nil
+}
+
+func (r *RevisionMemory) Get(ctx context.Context, songID, revision int) (*structure.SongRevision, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revisions := s.revisions[songID]
+	if revision < 1 || revision > len(revisions) {
+		return nil, ErrNotFound
+	}
+
+	found := revisions[revision-1]
+	return &found, nil
+}
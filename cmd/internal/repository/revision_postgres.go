@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RevisionPostgres struct {
+	db *gorm.DB
+}
+
+func (r *RevisionPostgres) Record(ctx context.Context, songID int, action, author string) (*structure.SongRevision, error) {
+	var recorded structure.SongRevision
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The song row lock serializes the revision numbers of the song.
+		var song structure.Song
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&song, songID).Error; err != nil {
+			return translateError(err)
+		}
+
+		if err := tx.Where("song_id = ?", songID).Limit(1).Find(&song.SongDetails).Error; err != nil {
+			return err
+		}
+
+		recorded = structure.NewSongRevision(&song, action, author)
+
+		var latest structure.SongRevision
+		err := tx.Where("song_id = ?", songID).Order("revision DESC").First(&latest).Error
+		switch {
+		case err == nil:
+			if latest.SameContent(&recorded) {
+				recorded = latest
+				return nil
+			}
+			recorded.Revision = latest.Revision + 1
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			recorded.Revision = 1
+		default:
+			return err
+		}
+
+		return tx.Create(&recorded).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &recorded, nil
+}
+
+func (r *RevisionPostgres) List(ctx context.Context, songID int) ([]structure.SongRevision, error) {
+	var revisions []structure.SongRevision
+	err := r.db.WithContext(ctx).Where("song_id = ?", songID).Order("revision").Find(&revisions).Error
+	return revisions, err
+}
+
+func (r *RevisionPostgres) Get(ctx context.Context, songID, revision int) (*structure.SongRevision, error) {
+	var found structure.SongRevision
+	err := r.db.WithContext(ctx).Where("song_id = ? AND revision = ?", songID, revision).First(&found).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return &found, nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+)
+
+// Search is a naive stand-in for the Postgres full-text search: every word of
+// the query must occur in the lyrics, case-insensitively, as a substring.
+func (r *SongMemory) Search(ctx context.Context, filter SearchFilter) ([]structure.SearchResult, error) {
+	terms := strings.FieldsFunc(strings.ToLower(filter.Query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	results := make([]structure.SearchResult, 0)
+	if len(terms) == 0 {
+		return results, nil
+	}
+
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for id := range s.songs {
+		song := s.song(id)
+		text := strings.ToLower(song.SongDetails.Text)
+
+		matchesAll := true
+		for _, term := range terms {
+			if !strings.Contains(text, term) {
+				matchesAll = false
+				break
+			}
+		}
+		if !matchesAll {
+			continue
+		}
+
+		var lines []int
+		var fragments []string
+		for i, line := range strings.Split(song.SongDetails.Text, "\n") {
+			if marked, ok := markTerms(line, terms); ok {
+				lines = append(lines, i+1)
+				if len(fragments) < 3 {
+					fragments = append(fragments, marked)
+				}
+			}
+		}
+
+		results = append(results, structure.SearchResult{
+			SongID:  song.ID,
+			Song:    song.Song,
+			Group:   song.Group.Name,
+			Rank:    float64(len(lines)),
+			Snippet: strings.Join(fragments, " … "),
+			Lines:   lines,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].SongID > results[j].SongID
+	})
+
+	start, end := page(len(results), filter.Limit, filter.Offset)
+	return results[start:end], nil
+}
+
+// markTerms wraps the occurrences of the terms in the line in <mark></mark>
+// and reports whether there were any. The rest of the line is HTML-escaped.
+func markTerms(line string, terms []string) (string, bool) {
+	runes := []rune(line)
+	lower := []rune(strings.ToLower(line))
+	marked := make([]bool, len(runes))
+	found := false
+
+	for _, term := range terms {
+		t := []rune(term)
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if string(lower[i:i+len(t)]) == term {
+				found = true
+				for j := i; j < i+len(t); j++ {
+					marked[j] = true
+				}
+			}
+		}
+	}
+
+	if !found {
+		return line, false
+	}
+
+	var b strings.Builder
+	for i, r := range runes {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			b.WriteString("<mark>")
+		}
+		b.WriteString(html.EscapeString(string(r)))
+		if marked[i] && (i == len(runes)-1 || !marked[i+1]) {
+			b.WriteString("</mark>")
+		}
+	}
+
+	return b.String(), true
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"html"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+)
+
+// searchQuery ranks the songs by their search_vector and collects the numbers
+// of the lines matching the query. Both the Russian and the English parsing of
+// the query are tried, as the lyrics are indexed with both.
+const searchQuery = `
+WITH q AS (
+    SELECT websearch_to_tsquery('russian', @query) || websearch_to_tsquery('english', @query) AS query
+)
+SELECT
+    s.id AS song_id,
+    s.song,
+    g.name AS "group",
+    ts_rank(d.search_vector, q.query) AS rank,
+    ts_headline(CAST(@config AS regconfig), translate(d.text, @selectors, ''), q.query,
+        'StartSel=' || @startSel || ', StopSel=' || @stopSel || ', MaxFragments=3, FragmentDelimiter=" … "') AS snippet,
+    array_to_string(ARRAY(
+        SELECT l.n
+        FROM unnest(string_to_array(d.text, E'\n')) WITH ORDINALITY AS l(line, n)
+        WHERE (to_tsvector('russian', l.line) || to_tsvector('english', l.line)) @@ q.query
+        ORDER BY l.n
+    ), ',') AS lines
+FROM q, song_details d
+JOIN songs s ON s.id = d.song_id
+JOIN groups g ON g.id = s.group_id
+WHERE d.search_vector @@ q.query AND s.deleted_at IS NULL
+ORDER BY rank DESC, s.id DESC
+LIMIT @limit OFFSET @offset`
+
+// ts_headline copies the lyrics as they are, so the matches are delimited by
+// control characters, removed from the lyrics beforehand, and replaced with
+// the <mark> tags once the rest of the snippet is escaped.
+const (
+	startSel = "\x02"
+	stopSel  = "\x03"
+)
+
+var snippetMarks = strings.NewReplacer(startSel, "<mark>", stopSel, "</mark>")
+
+type searchRow struct {
+	SongID  int
+	Song    string
+	Group   string
+	Rank    float64
+	Snippet string
+	Lines   string
+}
+
+func (r *SongPostgres) Search(ctx context.Context, filter SearchFilter) ([]structure.SearchResult, error) {
+	var rows []searchRow
+
+	err := r.db.WithContext(ctx).Raw(searchQuery, map[string]interface{}{
+		"query":     filter.Query,
+		"config":    headlineConfig(filter.Query),
+		"selectors": startSel + stopSel,
+		"startSel":  startSel,
+		"stopSel":   stopSel,
+		"limit":     filter.Limit,
+		"offset":    filter.Offset,
+	}).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]structure.SearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, structure.SearchResult{
+			SongID:  row.SongID,
+			Song:    row.Song,
+			Group:   row.Group,
+			Rank:    row.Rank,
+			Snippet: snippetMarks.Replace(html.EscapeString(row.Snippet)),
+			Lines:   parseLines(row.Lines),
+		})
+	}
+
+	return results, nil
+}
+
+// headlineConfig picks the text search configuration used to highlight the
+// snippet: Russian for queries with Cyrillic letters, English otherwise.
+func headlineConfig(query string) string {
+	for _, r := range query {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return "russian"
+		}
+	}
+	return "english"
+}
+
+func parseLines(s string) []int {
+	lines := make([]int, 0)
+	for _, part := range strings.Split(s, ",") {
+		if n, err := strconv.Atoi(part); err == nil {
+			lines = append(lines, n)
+		}
+	}
+	return lines
+}
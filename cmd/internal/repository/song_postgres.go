@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SongPostgres struct {
+	db *gorm.DB
+}
+
+func (r *SongPostgres) Create(ctx context.Context, song *structure.Song) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(song).Error; err != nil {
+			return translateError(err)
+		}
+
+		song.SongDetails.SongID = uint(song.ID)
+		return translateError(tx.Create(&song.SongDetails).Error)
+	})
+}
+
+// sortColumns maps the sortable fields to SQL expressions and the casts of
+// their keyset values.
+var sortColumns = map[string]struct{ expr, cast string }{
+	SortTitle:       {expr: "songs.song", cast: "?"},
+	SortGroup:       {expr: "groups.name", cast: "?"},
+	SortReleaseDate: {expr: "COALESCE(song_details.release_date, DATE '" + noReleaseDate + "')", cast: "?::date"},
+}
+
+func (r *SongPostgres) List(ctx context.Context, filter SongFilter) ([]structure.Song, error) {
+	var songs []structure.Song
+	query := r.filter(ctx, filter).Preload("Group")
+
+	backward := filter.Keyset != nil && filter.Keyset.Backward
+	if filter.Keyset != nil {
+		where, args := keysetCondition(filter.Sort, filter.Keyset)
+		query = query.Where(where, args...)
+	} else {
+		query = query.Offset(filter.Offset)
+	}
+
+	for _, field := range filter.Sort {
+		query = query.Order(sortColumns[field.Field].expr + direction(field.Desc != backward))
+	}
+	query = query.Order("songs.id" + direction(!backward))
+
+	if err := query.Limit(filter.Limit).Find(&songs).Error; err != nil {
+		return nil, err
+	}
+
+	if backward {
+		slices.Reverse(songs)
+	}
+
+	return songs, nil
+}
+
+func (r *SongPostgres) Count(ctx context.Context, filter SongFilter) (int64, error) {
+	var count int64
+	err := r.filter(ctx, filter).Count(&count).Error
+	return count, err
+}
+
+func (r *SongPostgres) SortKey(ctx context.Context, id int, sort []SortField) ([]string, error) {
+	if len(sort) == 0 {
+		return []string{}, nil
+	}
+
+	columns := make([]string, len(sort))
+	for i, field := range sort {
+		columns[i] = sortColumns[field.Field].expr + "::text"
+	}
+
+	row := r.joined(ctx).Select(strings.Join(columns, ", ")).Where("songs.id = ?", id).Row()
+
+	key := make([]string, len(sort))
+	dest := make([]interface{}, len(sort))
+	for i := range key {
+		dest[i] = &key[i]
+	}
+
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// joined selects the songs with their group and details joined, so that the
+// filters and sort fields may refer to them.
+func (r *SongPostgres) joined(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Model(&structure.Song{}).
+		Joins("JOIN groups ON groups.id = songs.group_id").
+		Joins("LEFT JOIN song_details ON song_details.song_id = songs.id")
+}
+
+func (r *SongPostgres) filter(ctx context.Context, filter SongFilter) *gorm.DB {
+	query := r.joined(ctx)
+
+	if filter.Song != "" {
+		query = query.Where("songs.song ILIKE ?", "%"+filter.Song+"%")
+	}
+
+	if filter.Group != "" {
+		query = query.Where("groups.name ILIKE ?", "%"+filter.Group+"%")
+	}
+
+	if filter.GroupID != 0 {
+		query = query.Where("songs.group_id = ?", filter.GroupID)
+	}
+
+	if filter.ReleasedAfter != nil {
+		query = query.Where("song_details.release_date >= ?", filter.ReleasedAfter.Format(time.DateOnly))
+	}
+
+	if filter.ReleasedBefore != nil {
+		query = query.Where("song_details.release_date <= ?", filter.ReleasedBefore.Format(time.DateOnly))
+	}
+
+	if filter.HasText != nil {
+		query = query.Where("(COALESCE(song_details.text, '') <> '') = ?", *filter.HasText)
+	}
+
+	if filter.HasLink != nil {
+		query = query.Where("(COALESCE(song_details.link, '') <> '') = ?", *filter.HasLink)
+	}
+
+	return query
+}
+
+// keysetCondition builds the condition selecting the songs after the keyset
+// song in the listing order (before it, for a backward keyset):
+//
+//	(f1 > v1) OR (f1 = v1 AND f2 > v2) OR ... OR (f1 = v1 AND ... AND id < vid)
+//
+// where the comparison of every field follows its sort direction.
+func keysetCondition(sort []SortField, keyset *Keyset) (string, []interface{}) {
+	var (
+		ors    []string
+		args   []interface{}
+		equals []string
+		eqArgs []interface{}
+	)
+
+	for i, field := range sort {
+		column := sortColumns[field.Field]
+		op := " > "
+		if field.Desc != keyset.Backward {
+			op = " < "
+		}
+
+		ors = append(ors, "("+strings.Join(append(slices.Clone(equals), column.expr+op+column.cast), " AND ")+")")
+		args = append(append(args, eqArgs...), keyset.Values[i])
+
+		equals = append(equals, column.expr+" = "+column.cast)
+		eqArgs = append(eqArgs, keyset.Values[i])
+	}
+
+	op := " < "
+	if keyset.Backward {
+		op = " > "
+	}
+
+	ors = append(ors, "("+strings.Join(append(equals, "songs.id"+op+"?"), " AND ")+")")
+	args = append(append(args, eqArgs...), keyset.ID)
+
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
+
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return " ASC"
+}
+
+func (r *SongPostgres) GetByID(ctx context.Context, id int) (*structure.Song, error) {
+	var song structure.Song
+	err := r.db.WithContext(ctx).Preload("Group").Preload("SongDetails").Where("id = ?", id).First(&song).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return &song, nil
+}
+
+func (r *SongPostgres) Text(ctx context.Context, id int) (string, error) {
+	var songDetail structure.SongDetails
+	err := r.db.WithContext(ctx).
+		Select("song_details.text").
+		Joins("JOIN songs ON songs.id = song_details.song_id AND songs.deleted_at IS NULL").
+		Where("song_details.song_id = ?", id).
+		First(&songDetail).Error
+	if err != nil {
+		return "", translateError(err)
+	}
+
+	return songDetail.Text, nil
+}
+
+func (r *SongPostgres) ReplaceInfo(ctx context.Context, id int, title string, details *structure.SongDetails) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&structure.Song{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"song":              title,
+			"enrichment_status": structure.EnrichmentDone,
+		})
+		if result.Error != nil {
+			return translateError(result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		if err := tx.Where("song_id = ?", id).Delete(&structure.SongDetails{}).Error; err != nil {
+			return err
+		}
+
+		details.ID = 0
+		details.SongID = uint(id)
+		return tx.Create(details).Error
+	})
+}
+
+func (r *SongPostgres) Update(ctx context.Context, id int, fields map[string]interface{}) error {
+	result := r.db.WithContext(ctx).Model(&structure.Song{}).Where("id = ?", id).Updates(fields)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *SongPostgres) UpdateDetails(ctx context.Context, id int, fields map[string]interface{}) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var song structure.Song
+		if err := tx.Select("id").First(&song, id).Error; err != nil {
+			return translateError(err)
+		}
+
+		result := tx.Model(&structure.SongDetails{}).Where("song_id = ?", id).Updates(fields)
+		if result.Error != nil || result.RowsAffected > 0 {
+			return result.Error
+		}
+
+		// The song has no details yet, e.g. its enrichment is pending.
+		row := map[string]interface{}{"song_id": id}
+		for column, value := range fields {
+			row[column] = value
+		}
+		return tx.Model(&structure.SongDetails{}).Create(row).Error
+	})
+}
+
+func (r *SongPostgres) Delete(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&structure.Song{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
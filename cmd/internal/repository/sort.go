@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+)
+
+// Sortable fields of a song listing.
+const (
+	SortTitle       = "title"
+	SortGroup       = "group"
+	SortReleaseDate = "release_date"
+)
+
+// noReleaseDate is the sort key of songs without a release date, they come
+// first in ascending order.
+const noReleaseDate = "0001-01-01"
+
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// IsSortField reports whether the song listing can be sorted by field.
+func IsSortField(field string) bool {
+	switch field {
+	case SortTitle, SortGroup, SortReleaseDate:
+		return true
+	}
+	return false
+}
+
+// songSortKey returns the values of the sort fields of a song with its group
+// and details attached.
+func songSortKey(song *structure.Song, sort []SortField) []string {
+	key := make([]string, len(sort))
+	for i, field := range sort {
+		switch field.Field {
+		case SortTitle:
+			key[i] = song.Song
+		case SortGroup:
+			key[i] = song.Group.Name
+		case SortReleaseDate:
+			key[i] = noReleaseDate
+			if !song.SongDetails.ReleaseDate.IsZero() {
+				key[i] = song.SongDetails.ReleaseDate.String()
+			}
+		}
+	}
+	return key
+}
+
+// compareSongKeys orders two songs by their sort keys and then by ID descending.
+func compareSongKeys(sort []SortField, a []string, aID int, b []string, bID int) int {
+	for i, field := range sort {
+		c := strings.Compare(a[i], b[i])
+		if field.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case aID > bID:
+		return -1
+	case aID < bID:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+	"gorm.io/gorm"
+)
+
+type TrashMemory struct {
+	store *memoryStore
+}
+
+func (r *TrashMemory) List(ctx context.Context, filter TrashFilter) ([]structure.Song, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	songs := make([]structure.Song, 0, len(s.trash))
+	for _, song := range s.trash {
+		song.Group = s.groups[song.GroupID]
+		songs = append(songs, song)
+	}
+
+	sort.Slice(songs, func(i, j int) bool {
+		if !songs[i].DeletedAt.Time.Equal(songs[j].DeletedAt.Time) {
+			return songs[i].DeletedAt.Time.After(songs[j].DeletedAt.Time)
+		}
+		return songs[i].ID > songs[j].ID
+	})
+
+	start, end := page(len(songs), filter.Limit, filter.Offset)
+	return songs[start:end], nil
+}
+
+func (r *TrashMemory) Restore(ctx context.Context, id int) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	song, ok := s.trash[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	song.DeletedAt = gorm.DeletedAt{}
+	s.songs[id] = song
+	delete(s.trash, id)
+	return nil
+}
+
+func (r *TrashMemory) Purge(ctx context.Context, id int) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.trash[id]; !ok {
+		return ErrNotFound
+	}
+
+	s.deleteSong(id)
+	return nil
+}
+
+func (r *TrashMemory) PurgeBefore(ctx context.Context, before time.Time) (int64, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for id, song := range s.trash {
+		if song.DeletedAt.Time.Before(before) {
+			s.deleteSong(id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
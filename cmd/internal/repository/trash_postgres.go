@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+	"gorm.io/gorm"
+)
+
+// TrashPostgres works on the soft deleted songs. Purging relies on the
+// foreign keys to remove the song details, lyrics and revisions.
+type TrashPostgres struct {
+	db *gorm.DB
+}
+
+func (r *TrashPostgres) List(ctx context.Context, filter TrashFilter) ([]structure.Song, error) {
+	var songs []structure.Song
+	err := r.db.WithContext(ctx).Unscoped().Preload("Group").
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").Order("id DESC").
+		Limit(filter.Limit).Offset(filter.Offset).
+		Find(&songs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return songs, nil
+}
+
+func (r *TrashPostgres) Restore(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Unscoped().Model(&structure.Song{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *TrashPostgres) Purge(ctx context.Context, id int) error {
+	result := r.db.WithContext(ctx).Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Delete(&structure.Song{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *TrashPostgres) PurgeBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at < ?", before).
+		Delete(&structure.Song{})
+
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,111 @@
+// Package revision compares song revisions.
+package revision
+
+import (
+	"strings"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+)
+
+// MaxDiffLines bounds the number of lines of each text compared line by line.
+// Longer texts are reported as changed without the line diff.
+const MaxDiffLines = 2000
+
+// Change is a field that differs between two revisions. The text changes
+// also come as a line diff, where every line is prefixed with "  ", "- " or
+// "+ ", unless LinesSkipped reports a text over MaxDiffLines.
+type Change struct {
+	Field        string      `json:"field"`
+	From         interface{} `json:"from"`
+	To           interface{} `json:"to"`
+	Lines        []string    `json:"lines,omitempty"`
+	LinesSkipped bool        `json:"lines_skipped,omitempty"`
+}
+
+// Diff returns the changes from one revision to another, a nil from is
+// compared as an empty song.
+func Diff(from, to *structure.SongRevision) []Change {
+	if from == nil {
+		from = &structure.SongRevision{}
+	}
+
+	changes := []Change{}
+	add := func(field string, a, b interface{}) {
+		changes = append(changes, Change{Field: field, From: a, To: b})
+	}
+
+	if from.Song != to.Song {
+		add("song", from.Song, to.Song)
+	}
+	if from.GroupID != to.GroupID {
+		add("group_id", from.GroupID, to.GroupID)
+	}
+	if !from.ReleaseDate.Equal(to.ReleaseDate.Time) {
+		add("release_date", from.ReleaseDate, to.ReleaseDate)
+	}
+	if from.Text != to.Text {
+		add("text", from.Text, to.Text)
+		change := &changes[len(changes)-1]
+		a, b := splitLines(from.Text), splitLines(to.Text)
+		if len(a) > MaxDiffLines || len(b) > MaxDiffLines {
+			change.LinesSkipped = true
+		} else {
+			change.Lines = diffLines(a, b)
+		}
+	}
+	if from.Link != to.Link {
+		add("link", from.Link, to.Link)
+	}
+
+	return changes
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
+
+// diffLines is a line diff based on the longest common subsequence. The
+// quadratic table is kept small by MaxDiffLines.
+func diffLines(a, b []string) []string {
+	lcs := make([][]int32, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "- "+a[i])
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, "- "+a[i])
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, "+ "+b[j])
+	}
+
+	return lines
+}
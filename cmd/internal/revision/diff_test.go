@@ -0,0 +1,53 @@
+package revision
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+)
+
+func TestDiffText(t *testing.T) {
+	from := &structure.SongRevision{Song: "Uprising", Text: "Paranoia is in bloom\nThe PR transmissions will resume"}
+	to := &structure.SongRevision{Song: "Uprising", Text: "Paranoia is in bloom\nThey'll try to push drugs\nThe PR transmissions will resume"}
+
+	changes := Diff(from, to)
+	if len(changes) != 1 || changes[0].Field != "text" {
+		t.Fatalf("changes %+v, want the text only", changes)
+	}
+
+	want := []string{"  Paranoia is in bloom", "+ They'll try to push drugs", "  The PR transmissions will resume"}
+	if !reflect.DeepEqual(changes[0].Lines, want) || changes[0].LinesSkipped {
+		t.Errorf("lines %q, want %q", changes[0].Lines, want)
+	}
+}
+
+func TestDiffLongText(t *testing.T) {
+	long := strings.Repeat("la\n", MaxDiffLines)
+
+	for _, tt := range []struct {
+		name     string
+		from, to string
+		skipped  bool
+	}{
+		{"at the limit", "", strings.TrimSuffix(long, "\n"), false},
+		{"longer from", long + "la", "la", true},
+		{"longer to", "la", long + "la", true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := Diff(&structure.SongRevision{Text: tt.from}, &structure.SongRevision{Text: tt.to})
+			if len(changes) != 1 || changes[0].Field != "text" {
+				t.Fatalf("changes %+v, want the text only", changes)
+			}
+
+			change := changes[0]
+			if change.LinesSkipped != tt.skipped || (len(change.Lines) == 0) != tt.skipped {
+				t.Errorf("skipped %v with %d lines, want skipped %v", change.LinesSkipped, len(change.Lines), tt.skipped)
+			}
+			if change.From != tt.from || change.To != tt.to {
+				t.Error("the texts of the change differ from the revisions")
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+// Package service implements the write flows of songs. A flow makes its
+// external API lookups first and then runs all of its writes, the revision
+// and the enrichment job in a single transaction, so that a failure at any
+// point leaves the song as it was.
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/apperr"
+	"github.com/qwaq-dev/test-api/cmd/internal/config"
+	"github.com/qwaq-dev/test-api/cmd/internal/enrichment"
+	"github.com/qwaq-dev/test-api/cmd/internal/musicinfo"
+	"github.com/qwaq-dev/test-api/cmd/internal/repository"
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+	"github.com/qwaq-dev/test-api/pkg/logger/sl"
+)
+
+var (
+	ErrSongNotFound  = apperr.NotFound("song_not_found", "Song not found")
+	ErrGroupNotFound = apperr.Validation("unknown_group", "Group not found", apperr.FieldError{
+		Field:   "group_id",
+		Code:    "not_found",
+		Message: "No group with this ID",
+	})
+	ErrRevisionNotFound = apperr.NotFound("revision_not_found", "Revision not found")
+	// ErrRevisionGroup means the group of the revision to restore was deleted.
+	ErrRevisionGroup = apperr.Conflict("revision_group_deleted", "Group of the revision no longer exists")
+)
+
+type Songs struct {
+	log             *slog.Logger
+	repo            *repository.Repository
+	songInfo        *musicinfo.Client
+	queue           *enrichment.Queue
+	degradedMode    bool
+	asyncEnrichment bool
+}
+
+func NewSongs(log *slog.Logger, cfg *config.Config, repo *repository.Repository, songInfo *musicinfo.Client, queue *enrichment.Queue) *Songs {
+	return &Songs{
+		log:             log,
+		repo:            repo,
+		songInfo:        songInfo,
+		queue:           queue,
+		degradedMode:    cfg.ExternalClient.DegradedMode,
+		asyncEnrichment: cfg.Enrichment.Async,
+	}
+}
+
+// Create stores the new song with its details and first revision. The
+// returned job is not nil when the details are left to the enrichment workers.
+func (s *Songs) Create(ctx context.Context, song *structure.Song, author string) (*structure.EnrichmentJob, error) {
+	group, err := s.repo.Groups.GetByID(ctx, song.GroupID)
+	if err != nil {
+		return nil, notFound(err, ErrGroupNotFound)
+	}
+
+	song.ID = 0
+	song.Group = *group
+	song.SongDetails = structure.SongDetails{}
+	song.EnrichmentStatus = structure.EnrichmentPending
+
+	if !s.asyncEnrichment {
+		details, err := s.lookup(ctx, group.Name, song.Song)
+		switch {
+		case err == nil:
+			song.SongDetails = *details
+			song.EnrichmentStatus = structure.EnrichmentDone
+		case s.degradedMode && musicinfo.Unavailable(err):
+			s.log.Warn("External API is unavailable, storing song without details", sl.Err(err))
+		default:
+			return nil, err
+		}
+	}
+
+	var job *structure.EnrichmentJob
+	err = s.repo.Atomic(ctx, func(tx *repository.Repository) error {
+		if err := tx.Songs.Create(ctx, song); err != nil {
+			return err
+		}
+
+		if _, err := tx.Revisions.Record(ctx, song.ID, structure.RevisionCreate, author); err != nil {
+			return err
+		}
+
+		if song.EnrichmentStatus == structure.EnrichmentPending {
+			job, err = tx.Enrichment.Enqueue(ctx, song.ID)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if job != nil {
+		s.queue.Wake()
+	}
+	return job, nil
+}
+
+// UpdateInfo renames the song and replaces its details with the ones from the
+// external API. The returned job is not nil when the details are left to the
+// enrichment workers.
+func (s *Songs) UpdateInfo(ctx context.Context, id int, title, author string) (*structure.EnrichmentJob, error) {
+	song, err := s.repo.Songs.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrSongNotFound)
+	}
+
+	if !s.asyncEnrichment {
+		details, err := s.lookup(ctx, song.Group.Name, title)
+		switch {
+		case err == nil:
+			err = s.repo.Atomic(ctx, func(tx *repository.Repository) error {
+				if err := tx.Songs.ReplaceInfo(ctx, id, title, details); err != nil {
+					return err
+				}
+
+				_, err := tx.Revisions.Record(ctx, id, structure.RevisionUpdate, author)
+				return err
+			})
+			return nil, notFound(err, ErrSongNotFound)
+		case s.degradedMode && musicinfo.Unavailable(err):
+			s.log.Warn("External API is unavailable, keeping old song details", sl.Err(err))
+		default:
+			return nil, err
+		}
+	}
+
+	var job *structure.EnrichmentJob
+	err = s.repo.Atomic(ctx, func(tx *repository.Repository) error {
+		err := tx.Songs.Update(ctx, id, map[string]interface{}{
+			"song":              title,
+			"enrichment_status": structure.EnrichmentPending,
+		})
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Revisions.Record(ctx, id, structure.RevisionUpdate, author); err != nil {
+			return err
+		}
+
+		job, err = tx.Enrichment.Enqueue(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, notFound(err, ErrSongNotFound)
+	}
+
+	s.queue.Wake()
+	return job, nil
+}
+
+// Patch updates the given columns of the song and of its details, validated
+// by the caller. A group_id is given as an int.
+func (s *Songs) Patch(ctx context.Context, id int, songFields, detailsFields map[string]interface{}, author string) error {
+	return s.repo.Atomic(ctx, func(tx *repository.Repository) error {
+		if _, err := tx.Songs.GetByID(ctx, id); err != nil {
+			return notFound(err, ErrSongNotFound)
+		}
+
+		if groupID, ok := songFields["group_id"]; ok {
+			gid, ok := groupID.(int)
+			if !ok {
+				return ErrGroupNotFound
+			}
+			if _, err := tx.Groups.GetByID(ctx, gid); err != nil {
+				return notFound(err, ErrGroupNotFound)
+			}
+		}
+
+		if len(songFields) > 0 {
+			if err := tx.Songs.Update(ctx, id, songFields); err != nil {
+				return err
+			}
+		}
+
+		if len(detailsFields) > 0 {
+			if err := tx.Songs.UpdateDetails(ctx, id, detailsFields); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.Revisions.Record(ctx, id, structure.RevisionPatch, author)
+		return err
+	})
+}
+
+// Delete moves the song to the trash.
+func (s *Songs) Delete(ctx context.Context, id int) error {
+	return notFound(s.repo.Songs.Delete(ctx, id), ErrSongNotFound)
+}
+
+// Restore brings the song back from the trash. A song whose details were
+// not fetched, because its job gave up on the trashed song or had not run
+// yet, is queued for enrichment again. repository.ErrNotFound is returned
+// for a song that is not in the trash.
+func (s *Songs) Restore(ctx context.Context, id int) error {
+	var job *structure.EnrichmentJob
+
+	err := s.repo.Atomic(ctx, func(tx *repository.Repository) error {
+		if err := tx.Trash.Restore(ctx, id); err != nil {
+			return err
+		}
+
+		song, err := tx.Songs.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if song.EnrichmentStatus != structure.EnrichmentDone {
+			job, err = tx.Enrichment.Enqueue(ctx, id)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if job != nil {
+		s.queue.Wake()
+	}
+	return nil
+}
+
+// RestoreRevision brings the song and its details back to the state of the
+// revision and records that as a new revision.
+func (s *Songs) RestoreRevision(ctx context.Context, id, revision int, author string) (*structure.SongRevision, error) {
+	var recorded *structure.SongRevision
+
+	err := s.repo.Atomic(ctx, func(tx *repository.Repository) error {
+		rev, err := tx.Revisions.Get(ctx, id, revision)
+		if err != nil {
+			return notFound(err, ErrRevisionNotFound)
+		}
+
+		if _, err := tx.Groups.GetByID(ctx, rev.GroupID); err != nil {
+			return notFound(err, ErrRevisionGroup)
+		}
+
+		err = tx.Songs.Update(ctx, id, map[string]interface{}{
+			"song":     rev.Song,
+			"group_id": rev.GroupID,
+		})
+		if err != nil {
+			return notFound(err, ErrSongNotFound)
+		}
+
+		err = tx.Songs.UpdateDetails(ctx, id, map[string]interface{}{
+			"release_date": rev.ReleaseDate,
+			"text":         rev.Text,
+			"link":         rev.Link,
+		})
+		if err != nil {
+			return err
+		}
+
+		recorded, err = tx.Revisions.Record(ctx, id, structure.RevisionRestore, author)
+		return err
+	})
+
+	return recorded, err
+}
+
+// lookup fetches the song details from the external API, a release date in
+// an unknown format is left empty.
+func (s *Songs) lookup(ctx context.Context, group, title string) (*structure.SongDetails, error) {
+	info, err := s.songInfo.Info(ctx, group, title)
+	if err != nil {
+		return nil, lookupError(err)
+	}
+
+	releaseDate, err := structure.ParseDate(info.ReleaseDate)
+	if err != nil {
+		s.log.Warn("Unknown release date format, leaving it empty", sl.Err(err))
+	}
+
+	return &structure.SongDetails{
+		ReleaseDate: releaseDate,
+		Text:        info.Text,
+		Link:        info.Link,
+	}, nil
+}
+
+// lookupError describes a failed external API lookup, the musicinfo error is
+// kept in the chain.
+func lookupError(err error) error {
+	switch musicinfo.Outcome(err) {
+	case musicinfo.OutcomeCircuitOpen:
+		return apperr.Unavailable("upstream_unavailable", "External API is temporarily unavailable", err)
+	case musicinfo.OutcomeNotFound:
+		e := apperr.Unprocessable("external_song_not_found", "Song is unknown to the external API")
+		return e.Wrap(err)
+	case musicinfo.OutcomeStatusError:
+		return apperr.Upstream("upstream_error", "External API returned an error", err)
+	case musicinfo.OutcomeDecodeError:
+		return apperr.Upstream("upstream_bad_response", "External API returned an invalid response", err)
+	case musicinfo.OutcomeTimeout:
+		return apperr.Timeout("upstream_timeout", "External API did not answer in time", err)
+	case musicinfo.OutcomeCanceled:
+		return err
+	}
+	return apperr.Upstream("upstream_unreachable", "Error connecting to external API", err)
+}
+
+// notFound replaces repository.ErrNotFound with the more specific target.
+func notFound(err error, target *apperr.Error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return target.Wrap(err)
+	}
+	return err
+}
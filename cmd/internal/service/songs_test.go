@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/config"
+	"github.com/qwaq-dev/test-api/cmd/internal/enrichment"
+	"github.com/qwaq-dev/test-api/cmd/internal/musicinfo"
+	"github.com/qwaq-dev/test-api/cmd/internal/repository"
+	"github.com/qwaq-dev/test-api/cmd/internal/structure"
+)
+
+var errInjected = errors.New("injected failure")
+
+// Operations of the repository that can be made to fail.
+const (
+	failSongsCreate        = "Songs.Create"
+	failSongsUpdate        = "Songs.Update"
+	failSongsUpdateDetails = "Songs.UpdateDetails"
+	failSongsReplaceInfo   = "Songs.ReplaceInfo"
+	failRevisionsRecord    = "Revisions.Record"
+	failEnrichmentEnqueue  = "Enrichment.Enqueue"
+)
+
+type faultySongs struct {
+	repository.SongRepository
+	fail string
+}
+
+func (r faultySongs) Create(ctx context.Context, song *structure.Song) error {
+	if r.fail == failSongsCreate {
+		return errInjected
+	}
+	return r.SongRepository.Create(ctx, song)
+}
+
+func (r faultySongs) Update(ctx context.Context, id int, fields map[string]interface{}) error {
+	if r.fail == failSongsUpdate {
+		return errInjected
+	}
+	return r.SongRepository.Update(ctx, id, fields)
+}
+
+func (r faultySongs) UpdateDetails(ctx context.Context, id int, fields map[string]interface{}) error {
+	if r.fail == failSongsUpdateDetails {
+		return errInjected
+	}
+	return r.SongRepository.UpdateDetails(ctx, id, fields)
+}
+
+func (r faultySongs) ReplaceInfo(ctx context.Context, id int, title string, details *structure.SongDetails) error {
+	if r.fail == failSongsReplaceInfo {
+		return errInjected
+	}
+	return r.SongRepository.ReplaceInfo(ctx, id, title, details)
+}
+
+type faultyRevisions struct {
+	repository.RevisionRepository
+	fail string
+}
+
+func (r faultyRevisions) Record(ctx context.Context, songID int, action, author string) (*structure.SongRevision, error) {
+	if r.fail == failRevisionsRecord {
+		return nil, errInjected
+	}
+	return r.RevisionRepository.Record(ctx, songID, action, author)
+}
+
+type faultyEnrichment struct {
+	repository.EnrichmentRepository
+	fail string
+}
+
+func (r faultyEnrichment) Enqueue(ctx context.Context, songID int) (*structure.EnrichmentJob, error) {
+	if r.fail == failEnrichmentEnqueue {
+		return nil, errInjected
+	}
+	return r.EnrichmentRepository.Enqueue(ctx, songID)
+}
+
+// failing returns repo with the fail operation always returning errInjected,
+// inside transactions too.
+func failing(repo *repository.Repository, fail string) *repository.Repository {
+	return repo.Intercept(func(r *repository.Repository) {
+		r.Songs = faultySongs{SongRepository: r.Songs, fail: fail}
+		r.Revisions = faultyRevisions{RevisionRepository: r.Revisions, fail: fail}
+		r.Enrichment = faultyEnrichment{EnrichmentRepository: r.Enrichment, fail: fail}
+	})
+}
+
+// testLyrics are the lyrics the external API returns for each song of Muse.
+var testLyrics = map[string]string{
+	"Supermassive Black Hole": "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?",
+	"Uprising":                "Paranoia is in bloom\nThe PR transmissions will resume",
+}
+
+// newTestRepo returns a memory repository holding the group Muse and an
+// external API that knows the songs of testLyrics.
+func newTestRepo(t *testing.T) (*repository.Repository, string) {
+	t.Helper()
+
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		text, ok := testLyrics[r.URL.Query().Get("song")]
+		if r.URL.Query().Get("group") != "Muse" || !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(musicinfo.SongInfo{ReleaseDate: "16.07.2006", Text: text})
+	}))
+	t.Cleanup(external.Close)
+
+	repo := repository.NewMemoryRepository()
+	if err := repo.Groups.Create(context.Background(), &structure.Group{Name: "Muse"}); err != nil {
+		t.Fatal(err)
+	}
+
+	return repo, external.URL
+}
+
+// newTestSongs returns the service over repo, looking songs up inline or
+// leaving them to the enrichment workers, which are not running.
+func newTestSongs(repo *repository.Repository, externalAPI string, async bool) *Songs {
+	cfg := &config.Config{
+		ExternalAPI:    externalAPI,
+		ExternalClient: config.ExternalClient{Timeout: time.Second},
+		Enrichment:     config.Enrichment{Async: async},
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	songInfo := musicinfo.New(cfg.ExternalAPI, cfg.ExternalClient, nil)
+	queue := enrichment.NewQueue(log, cfg.Enrichment, repo, songInfo)
+	return NewSongs(log, cfg, repo, songInfo, queue)
+}
+
+// state is everything the write flows may change about a song.
+type state struct {
+	Song      *structure.Song
+	Trashed   []structure.Song
+	Revisions []structure.SongRevision
+	Job       *structure.EnrichmentJob
+}
+
+func snapshot(t *testing.T, repo *repository.Repository, id int) state {
+	t.Helper()
+	ctx := context.Background()
+
+	var s state
+	var err error
+
+	s.Song, err = repo.Songs.GetByID(ctx, id)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		t.Fatal(err)
+	}
+
+	if s.Trashed, err = repo.Trash.List(ctx, repository.TrashFilter{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Revisions, err = repo.Revisions.List(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Job, err = repo.Enrichment.Latest(ctx, id)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+// expectRollback runs flow against a repository failing at each of fails and
+// checks that the error is returned and the song is left as it was. Then it
+// checks that the flow changes the song when nothing fails.
+func expectRollback(t *testing.T, repo *repository.Repository, id int, fails []string, flow func(repo *repository.Repository) error) {
+	t.Helper()
+
+	before := snapshot(t, repo, id)
+
+	for _, fail := range fails {
+		t.Run(fail, func(t *testing.T) {
+			if err := flow(failing(repo, fail)); !errors.Is(err, errInjected) {
+				t.Fatalf("error %v, want the injected failure", err)
+			}
+
+			if after := snapshot(t, repo, id); !reflect.DeepEqual(after, before) {
+				t.Errorf("state changed\nbefore %+v\nafter  %+v", before, after)
+			}
+		})
+	}
+
+	if err := flow(repo); err != nil {
+		t.Fatalf("flow without failures: %v", err)
+	}
+
+	if after := snapshot(t, repo, id); reflect.DeepEqual(after, before) {
+		t.Errorf("flow without failures left the state unchanged: %+v", after)
+	}
+}
+
+// createSong stores a Supermassive Black Hole looked up inline and returns
+// its ID.
+func createSong(t *testing.T, repo *repository.Repository, externalAPI string) int {
+	t.Helper()
+
+	song := &structure.Song{Song: "Supermassive Black Hole", GroupID: 1}
+	if _, err := newTestSongs(repo, externalAPI, false).Create(context.Background(), song, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	return song.ID
+}
+
+func TestCreateRollback(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		fails := []string{failSongsCreate, failRevisionsRecord}
+		name := "sync"
+		if async {
+			fails = append(fails, failEnrichmentEnqueue)
+			name = "async"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			repo, externalAPI := newTestRepo(t)
+
+			expectRollback(t, repo, 1, fails, func(repo *repository.Repository) error {
+				song := &structure.Song{Song: "Supermassive Black Hole", GroupID: 1}
+				_, err := newTestSongs(repo, externalAPI, async).Create(context.Background(), song, "test")
+				return err
+			})
+		})
+	}
+}
+
+func TestUpdateInfoRollback(t *testing.T) {
+	t.Run("sync", func(t *testing.T) {
+		repo, externalAPI := newTestRepo(t)
+		id := createSong(t, repo, externalAPI)
+
+		expectRollback(t, repo, id, []string{failSongsReplaceInfo, failRevisionsRecord}, func(repo *repository.Repository) error {
+			_, err := newTestSongs(repo, externalAPI, false).UpdateInfo(context.Background(), id, "Uprising", "test")
+			return err
+		})
+	})
+
+	t.Run("async", func(t *testing.T) {
+		repo, externalAPI := newTestRepo(t)
+		id := createSong(t, repo, externalAPI)
+
+		fails := []string{failSongsUpdate, failRevisionsRecord, failEnrichmentEnqueue}
+		expectRollback(t, repo, id, fails, func(repo *repository.Repository) error {
+			_, err := newTestSongs(repo, externalAPI, true).UpdateInfo(context.Background(), id, "Uprising", "test")
+			return err
+		})
+	})
+}
+
+func TestPatchRollback(t *testing.T) {
+	repo, externalAPI := newTestRepo(t)
+	id := createSong(t, repo, externalAPI)
+
+	fails := []string{failSongsUpdate, failSongsUpdateDetails, failRevisionsRecord}
+	expectRollback(t, repo, id, fails, func(repo *repository.Repository) error {
+		return newTestSongs(repo, externalAPI, false).Patch(context.Background(), id,
+			map[string]interface{}{"song": "Starlight"},
+			map[string]interface{}{"text": "Far away, this ship has taken me far away"},
+			"test",
+		)
+	})
+}
+
+func TestRestoreRevisionRollback(t *testing.T) {
+	repo, externalAPI := newTestRepo(t)
+	id := createSong(t, repo, externalAPI)
+
+	err := newTestSongs(repo, externalAPI, false).Patch(context.Background(), id,
+		map[string]interface{}{"song": "Starlight"},
+		map[string]interface{}{"text": "Far away, this ship has taken me far away"},
+		"test",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fails := []string{failSongsUpdate, failSongsUpdateDetails, failRevisionsRecord}
+	expectRollback(t, repo, id, fails, func(repo *repository.Repository) error {
+		_, err := newTestSongs(repo, externalAPI, false).RestoreRevision(context.Background(), id, 1, "test")
+		return err
+	})
+}
+
+func TestRestoreRollback(t *testing.T) {
+	repo, externalAPI := newTestRepo(t)
+	songs := newTestSongs(repo, externalAPI, true)
+
+	song := &structure.Song{Song: "Supermassive Black Hole", GroupID: 1}
+	if _, err := songs.Create(context.Background(), song, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := songs.Delete(context.Background(), song.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	expectRollback(t, repo, song.ID, []string{failEnrichmentEnqueue}, func(repo *repository.Repository) error {
+		return newTestSongs(repo, externalAPI, true).Restore(context.Background(), song.ID)
+	})
+}
@@ -0,0 +1,24 @@
+package structure
+
+import "time"
+
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobDead    = "dead"
+)
+
+// EnrichmentJob is a queued lookup of SongDetails in the external API.
+// A running job whose RunAt has passed is considered abandoned and is picked
+// up again.
+type EnrichmentJob struct {
+	ID        int       `json:"id" gorm:"primaryKey"`
+	SongID    int       `json:"song_id" gorm:"not null;index"`
+	Status    string    `json:"status" gorm:"not null;index"`
+	Attempts  int       `json:"attempts" gorm:"not null;default:0"`
+	LastError string    `json:"last_error,omitempty"`
+	RunAt     time.Time `json:"run_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,14 @@
+package structure
+
+// SearchResult is a song found by its lyrics.
+type SearchResult struct {
+	SongID int     `json:"song_id"`
+	Song   string  `json:"song"`
+	Group  string  `json:"group"`
+	Rank   float64 `json:"rank"`
+	// Snippet holds the matching fragments of the lyrics as HTML: the text
+	// is escaped and the matches are wrapped in <mark></mark>.
+	Snippet string `json:"snippet"`
+	// Lines are the 1-based numbers of the lyric lines that match.
+	Lines []int `json:"lines"`
+}
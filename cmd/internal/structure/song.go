@@ -1,14 +1,25 @@
 package structure
 
+import "gorm.io/gorm"
+
 type Group struct {
 	ID   int    `json:"id" gorm:"primaryKey"`
 	Name string `json:"name" gorm:"unique;not null"`
 }
 
+const (
+	EnrichmentDone    = "done"
+	EnrichmentPending = "pending"
+	EnrichmentFailed  = "failed"
+)
+
 type Song struct {
-	ID          int         `json:"id" gorm:"primaryKey"`
-	Song        string      `json:"song" gorm:"not null"`
-	GroupID     int         `json:"group_id"`
-	Group       Group       `json:"group" gorm:"foreignKey:GroupID"`
-	SongDetails SongDetails `json:"song_details" gorm:"foreignKey:SongID"`
+	ID               int         `json:"id" gorm:"primaryKey"`
+	Song             string      `json:"song" gorm:"not null"`
+	GroupID          int         `json:"group_id"`
+	Group            Group       `json:"group" gorm:"foreignKey:GroupID"`
+	SongDetails      SongDetails `json:"song_details" gorm:"foreignKey:SongID"`
+	EnrichmentStatus string      `json:"enrichment_status" gorm:"not null;default:done"`
+	// DeletedAt is set while the song is in the trash.
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index" swaggertype:"string" format:"date-time"`
 }
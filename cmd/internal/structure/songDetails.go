@@ -3,7 +3,7 @@ package structure
 type SongDetails struct {
 	ID          uint   `gorm:"primaryKey" json:"id"`
 	SongID      uint   `gorm:"foreignKey" json:"song_id"`
-	ReleaseDate string `json:"release_date"`
+	ReleaseDate Date   `json:"release_date" swaggertype:"string" format:"date" example:"2006-07-16"`
 	Text        string `json:"text"`
 	Link        string `json:"link"`
 }
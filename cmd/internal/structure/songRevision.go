@@ -0,0 +1,52 @@
+package structure
+
+import "time"
+
+// Actions recorded in song revisions.
+const (
+	RevisionImport     = "import"
+	RevisionCreate     = "create"
+	RevisionUpdate     = "update"
+	RevisionPatch      = "patch"
+	RevisionEnrichment = "enrichment"
+	RevisionRestore    = "restore"
+)
+
+// SongRevision is a snapshot of a song and its details taken after a change.
+// Revisions are numbered from 1 for every song.
+type SongRevision struct {
+	ID          int       `json:"-" gorm:"primaryKey"`
+	SongID      int       `json:"song_id" gorm:"not null"`
+	Revision    int       `json:"revision" gorm:"not null"`
+	Action      string    `json:"action" gorm:"not null"`
+	Author      string    `json:"author" gorm:"not null"`
+	Song        string    `json:"song" gorm:"not null"`
+	GroupID     int       `json:"group_id" gorm:"not null"`
+	ReleaseDate Date      `json:"release_date" swaggertype:"string" format:"date"`
+	Text        string    `json:"text"`
+	Link        string    `json:"link"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NewSongRevision takes a snapshot of the song with its details attached.
+func NewSongRevision(song *Song, action, author string) SongRevision {
+	return SongRevision{
+		SongID:      song.ID,
+		Action:      action,
+		Author:      author,
+		Song:        song.Song,
+		GroupID:     song.GroupID,
+		ReleaseDate: song.SongDetails.ReleaseDate,
+		Text:        song.SongDetails.Text,
+		Link:        song.SongDetails.Link,
+	}
+}
+
+// SameContent reports whether both revisions hold the same song state.
+func (r *SongRevision) SameContent(other *SongRevision) bool {
+	return r.Song == other.Song &&
+		r.GroupID == other.GroupID &&
+		r.ReleaseDate.Equal(other.ReleaseDate.Time) &&
+		r.Text == other.Text &&
+		r.Link == other.Link
+}
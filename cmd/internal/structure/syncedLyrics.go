@@ -0,0 +1,15 @@
+package structure
+
+import "time"
+
+// SyncedLyrics are the time-synced lyrics of a song, kept as uploaded in the
+// LRC format.
+type SyncedLyrics struct {
+	SongID    int       `json:"song_id" gorm:"primaryKey;autoIncrement:false"`
+	LRC       string    `json:"lrc" gorm:"column:lrc;not null"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (SyncedLyrics) TableName() string {
+	return "synced_lyrics"
+}
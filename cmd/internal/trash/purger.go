@@ -0,0 +1,70 @@
+// Package trash purges the songs that stayed in the trash longer than the
+// retention period.
+package trash
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/config"
+	"github.com/qwaq-dev/test-api/cmd/internal/repository"
+	"github.com/qwaq-dev/test-api/pkg/logger/sl"
+)
+
+type Purger struct {
+	log   *slog.Logger
+	cfg   config.Trash
+	trash repository.TrashRepository
+}
+
+func NewPurger(log *slog.Logger, cfg config.Trash, trash repository.TrashRepository) *Purger {
+	return &Purger{
+		log:   log,
+		cfg:   cfg,
+		trash: trash,
+	}
+}
+
+// Run purges the expired songs every purge interval until ctx is cancelled.
+// It returns right away when the retention is disabled.
+func (p *Purger) Run(ctx context.Context) {
+	if p.cfg.Retention <= 0 {
+		p.log.Info("Trash retention is disabled, deleted songs are kept until purged")
+		return
+	}
+
+	interval := p.cfg.PurgeInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Purger) purge(ctx context.Context) {
+	before := time.Now().Add(-p.cfg.Retention)
+
+	purged, err := p.trash.PurgeBefore(ctx, before)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.log.Error("Failed to purge trash", sl.Err(err))
+		}
+		return
+	}
+
+	if purged > 0 {
+		p.log.Info("Expired songs purged from trash", slog.Int64("songs", purged), slog.Time("deleted_before", before))
+	}
+}
@@ -0,0 +1,60 @@
+// Package version reports the build information of the binary.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set at build time, for example:
+//
+//	go build -ldflags "-X github.com/qwaq-dev/test-api/cmd/internal/version.Version=v1.2.0 \
+//		-X github.com/qwaq-dev/test-api/cmd/internal/version.Commit=$(git rev-parse HEAD) \
+//		-X github.com/qwaq-dev/test-api/cmd/internal/version.BuildTime=$(date -u +%FT%TZ)"
+//
+// Commit and BuildTime fall back to the VCS information embedded by the Go
+// toolchain when left empty.
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.BuildTime == "" {
+				info.BuildTime = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+
+	return info
+}
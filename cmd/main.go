@@ -9,64 +9,262 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/swagger"
 	_ "github.com/qwaq-dev/test-api/cmd/docs"
 	"github.com/qwaq-dev/test-api/cmd/internal/config"
+	"github.com/qwaq-dev/test-api/cmd/internal/enrichment"
 	"github.com/qwaq-dev/test-api/cmd/internal/handler"
+	"github.com/qwaq-dev/test-api/cmd/internal/health"
+	"github.com/qwaq-dev/test-api/cmd/internal/metrics"
+	"github.com/qwaq-dev/test-api/cmd/internal/middleware"
+	"github.com/qwaq-dev/test-api/cmd/internal/migrator"
+	"github.com/qwaq-dev/test-api/cmd/internal/musicinfo"
 	"github.com/qwaq-dev/test-api/cmd/internal/repository"
+	"github.com/qwaq-dev/test-api/cmd/internal/service"
+	"github.com/qwaq-dev/test-api/cmd/internal/tracing"
+	"github.com/qwaq-dev/test-api/cmd/internal/trash"
+	"github.com/qwaq-dev/test-api/pkg/logger"
+	"github.com/qwaq-dev/test-api/pkg/logger/sl"
 )
 
 const (
-	envDev  = "dev"
-	envProd = "prod"
+	envDev = "dev"
+
+	driverPostgres = "postgres"
+	driverMemory   = "memory"
 )
 
 func main() {
-	app := fiber.New()
 	cfg := config.MustLoad()
-	log := setupLogger(cfg.Env)
+	log, logOutput, err := setupLogger(cfg.Env, cfg.Logging)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot set up logger: %s\n", err)
+		os.Exit(1)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(cfg.Database, log, os.Args[2:]))
+	}
 
-	err := repository.NewPostgresDB(cfg.Database, log)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
 	if err != nil {
-		log.Error("Error connecting to database", slog.String("error", err.Error()))
+		log.Error("Failed to set up tracing", sl.Err(err))
 		os.Exit(1)
 	}
 
-	api := app.Group("/api")
-	h := handler.NewHandler(log, cfg.ExternalAPI)
+	prom := metrics.New()
+
+	repo, m, err := setupRepository(cfg.Database, log, prom)
+	if err != nil {
+		log.Error("Error connecting to database", sl.Err(err))
+		os.Exit(1)
+	}
+
+	app := fiber.New(fiber.Config{
+		ReadTimeout:  cfg.HTTPServer.ReadTimeout,
+		WriteTimeout: cfg.HTTPServer.WriteTimeout,
+		IdleTimeout:  cfg.HTTPServer.IdleTimeout,
+		BodyLimit:    cfg.HTTPServer.BodyLimit,
+		ErrorHandler: handler.ErrorHandler(log),
+	})
+	app.Use(
+		tracing.Middleware(),
+		prom.Middleware(),
+		middleware.RequestID(),
+		middleware.AccessLog(log),
+	)
+
+	// requestsCtx is cancelled once the shutdown grace period is over, so the
+	// requests still running stop their queries and external calls.
+	requestsCtx, cancelRequests := context.WithCancel(context.Background())
+	api := app.Group("/api", middleware.Timeout(requestsCtx, cfg.HTTPServer.RequestTimeout))
+	songInfo := musicinfo.New(cfg.ExternalAPI, cfg.ExternalClient, prom)
+	queue := enrichment.NewQueue(log, cfg.Enrichment, repo, songInfo)
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(2)
+	go func() {
+		defer workers.Done()
+		queue.Run(workersCtx)
+	}()
+	go func() {
+		defer workers.Done()
+		trash.NewPurger(log, cfg.Trash, repo.Trash).Run(workersCtx)
+	}()
+
+	songs := service.NewSongs(log, cfg, repo, songInfo, queue)
+	checker := health.NewChecker(cfg.Health.Timeout, readinessChecks(cfg, repo, m, songInfo)...)
+	h := handler.NewHandler(log, cfg, repo, songs, queue, checker)
+
+	app.Get("/healthz", h.Healthz)
+	app.Get("/readyz", h.Readyz)
+	app.Get("/version", h.Version)
+	app.Get("/metrics", prom.Handler())
 
 	api.Get("/songs", h.AllSongs)         //+
 	api.Get("/song/:id", h.SongById)      //+
 	api.Get("/song/:id/text", h.SongText) //+
+	api.Get("/song/:id/enrichment", h.SongEnrichment)
+	api.Get("/song/:id/lyrics", h.SongLyrics)
+	api.Put("/song/:id/lyrics", h.UploadLyrics)
+	api.Delete("/song/:id/lyrics", h.DeleteLyrics)
+	api.Get("/song/:id/revisions", h.SongRevisions)
+	api.Get("/song/:id/revisions/diff", h.DiffRevisions)
+	api.Post("/song/:id/revisions/:rev/restore", h.RestoreRevision)
+	api.Get("/search", h.Search)
 
 	api.Post("/songs", h.CreateSong)       //+
 	api.Put("/song/:id", h.UpdateSongInfo) //+
 	api.Patch("/song/:id", h.PartialUpdateSong)
 	api.Delete("/song/:id", h.DeleteSong) //+
 
+	api.Get("/trash", h.Trash)
+	api.Post("/trash/:id/restore", h.RestoreSong)
+	api.Delete("/trash/:id", h.PurgeSong)
+
+	api.Get("/groups", h.AllGroups)
+	api.Get("/group/:id", h.GroupById)
+	api.Post("/groups", h.CreateGroup)
+	api.Put("/group/:id", h.RenameGroup)
+	api.Delete("/group/:id", h.DeleteGroup)
+
 	app.Get("/swagger/*", swagger.HandlerDefault) // default
 
-	log.Info("Server started", slog.String("port", cfg.HTTPServer.Port))
-	app.Listen(cfg.HTTPServer.Port)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Info("Server started", slog.String("port", cfg.HTTPServer.Port))
+		serverErr <- app.Listen(cfg.HTTPServer.Port)
+	}()
+
+	exitCode := 0
+	select {
+	case err := <-serverErr:
+		log.Error("Server stopped unexpectedly", sl.Err(err))
+		exitCode = 1
+	case <-ctx.Done():
+		log.Info("Shutting down", sl.Duration("grace_period", cfg.HTTPServer.ShutdownTimeout))
+		if err := app.ShutdownWithTimeout(cfg.HTTPServer.ShutdownTimeout); err != nil {
+			log.Error("In-flight requests did not finish in time", sl.Err(err))
+			exitCode = 1
+		}
+	}
+	stop()
+	cancelRequests()
+
+	stopWorkers()
+	workers.Wait()
+
+	if err := repo.Close(); err != nil {
+		log.Error("Failed to close database", sl.Err(err))
+		exitCode = 1
+	}
+
+	tracingCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(tracingCtx); err != nil {
+		log.Error("Failed to flush traces", sl.Err(err))
+	}
+	cancel()
+
+	log.Info("Server stopped")
+	logOutput.Close()
+	os.Exit(exitCode)
+}
+
+// setupRepository connects to the configured storage. The returned Migrator
+// is nil for the memory driver, which has no schema.
+func setupRepository(cfg config.Database, log *slog.Logger, prom *metrics.Metrics) (*repository.Repository, *migrator.Migrator, error) {
+	switch cfg.Driver {
+	case driverMemory:
+		log.Warn("Using in-memory storage, data will be lost on restart")
+		return repository.NewMemoryRepository(), nil, nil
+	case driverPostgres:
+		db, err := repository.NewPostgresDB(cfg, log)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		m, err := newMigrator(db)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err := checkSchema(cfg, m, log); err != nil {
+			return nil, nil, err
+		}
+
+		if err := prom.InstrumentGORM(db); err != nil {
+			return nil, nil, err
+		}
+
+		if err := tracing.InstrumentGORM(db); err != nil {
+			return nil, nil, err
+		}
+
+		return repository.NewPostgresRepository(db), m, nil
+	}
+
+	return nil, nil, fmt.Errorf("unknown database driver %q", cfg.Driver)
 }
 
-func setupLogger(env string) *slog.Logger {
-	var log *slog.Logger
-
-	switch env {
-	case envDev:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
-		)
-	case envProd:
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
-		)
+func readinessChecks(cfg *config.Config, repo *repository.Repository, m *migrator.Migrator, songInfo *musicinfo.Client) []health.Check {
+	checks := []health.Check{
+		{Name: "database", Probe: repo.Ping},
+	}
+
+	if m != nil {
+		checks = append(checks, health.Check{Name: "migrations", Probe: m.Check})
+	}
+
+	if cfg.Health.ExternalAPI {
+		checks = append(checks, health.Check{
+			Name:     "external_api",
+			Probe:    songInfo.Ping,
+			Optional: true,
+			TTL:      cfg.Health.ExternalAPITTL,
+		})
+	}
+
+	return checks
+}
+
+// setupLogger builds the logger described by the logging config. When no
+// level is set, dev logs at debug and every other env at info.
+func setupLogger(env string, cfg config.Logging) (*slog.Logger, io.Closer, error) {
+	level := slog.LevelInfo
+	if env == envDev {
+		level = slog.LevelDebug
+	}
+
+	if cfg.Level != "" {
+		var err error
+		if level, err = logger.ParseLevel(cfg.Level); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	return log
+	return logger.New(logger.Options{
+		Level:       level,
+		Format:      cfg.Format,
+		Output:      cfg.Output,
+		MaxSizeMB:   cfg.MaxSizeMB,
+		MaxBackups:  cfg.MaxBackups,
+		MaxAgeDays:  cfg.MaxAgeDays,
+		Compress:    cfg.Compress,
+		Redact:      cfg.Redact,
+		MaxAttrSize: cfg.MaxAttrSize,
+	})
 }
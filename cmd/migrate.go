@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/qwaq-dev/test-api/cmd/internal/config"
+	"github.com/qwaq-dev/test-api/cmd/internal/migrator"
+	"github.com/qwaq-dev/test-api/cmd/internal/repository"
+	"github.com/qwaq-dev/test-api/migrations"
+	"github.com/qwaq-dev/test-api/pkg/logger/sl"
+	"gorm.io/gorm"
+)
+
+const migrateUsage = "usage: migrate up | down | status | to <version>"
+
+func newMigrator(db *gorm.DB) (*migrator.Migrator, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	return migrator.New(sqlDB, migrations.FS)
+}
+
+// checkSchema refuses to start against an out-of-date schema, or migrates it
+// when database.auto_migrate is set.
+func checkSchema(cfg config.Database, m *migrator.Migrator, log *slog.Logger) error {
+	ctx := context.Background()
+
+	if cfg.AutoMigrate {
+		if err := m.Up(ctx); err != nil {
+			log.Error("Migration failed", sl.Err(err))
+			return err
+		}
+	}
+
+	if err := m.Check(ctx); err != nil {
+		log.Error("Run the migrations with the migrate up command", sl.Err(err))
+		return err
+	}
+
+	log.Info("Database schema is up to date", slog.Int("version", m.Latest()))
+	return nil
+}
+
+// runMigrate implements the migrate subcommand and returns the exit code.
+func runMigrate(cfg config.Database, log *slog.Logger, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	if cfg.Driver != driverPostgres {
+		log.Error("Migrations are only supported by the postgres driver", slog.String("driver", cfg.Driver))
+		return 1
+	}
+
+	db, err := repository.NewPostgresDB(cfg, log)
+	if err != nil {
+		return 1
+	}
+
+	m, err := newMigrator(db)
+	if err != nil {
+		log.Error("Failed to load migrations", sl.Err(err))
+		return 1
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		err = m.Up(ctx)
+	case "down":
+		err = m.Down(ctx)
+	case "to":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 2
+		}
+
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil || version < 0 {
+			fmt.Fprintf(os.Stderr, "invalid version %q\n", args[1])
+			return 2
+		}
+
+		err = m.To(ctx, version)
+	case "status":
+		statuses, statusErr := m.Status(ctx)
+		if statusErr != nil {
+			log.Error("Failed to get migrations status", sl.Err(statusErr))
+			return 1
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(statuses)
+		return 0
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	if err != nil {
+		log.Error("Migration failed", slog.String("command", args[0]), sl.Err(err))
+		return 1
+	}
+
+	version, err := m.Version(ctx)
+	if err != nil {
+		log.Error("Failed to get schema version", sl.Err(err))
+		return 1
+	}
+
+	log.Info("Migration finished", slog.String("command", args[0]), slog.Int("version", version))
+	return 0
+}
@@ -0,0 +1,196 @@
+// Command mockinfo serves a local stand-in of the external music info API
+// (GET /info?group=&song=) from a fixtures file. Faults can be injected with
+// flags for every request, per fixture with the "fault" field, or per request
+// with the X-Mock-Fault header.
+//
+//	go run ./cmd/mockinfo -fixtures cmd/mockinfo/fixtures.yaml -latency 200ms -error-rate 0.1 -fault-delay 6s
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	faultNone      = ""
+	faultLatency   = "latency"
+	faultNotFound  = "404"
+	faultError     = "500"
+	faultMalformed = "malformed"
+)
+
+type fixture struct {
+	Group       string `yaml:"group" json:"group"`
+	Song        string `yaml:"song" json:"song"`
+	ReleaseDate string `yaml:"release_date" json:"release_date"`
+	Text        string `yaml:"text" json:"text"`
+	Link        string `yaml:"link" json:"link"`
+	Fault       string `yaml:"fault" json:"fault"`
+}
+
+type fixtures struct {
+	Songs []fixture `yaml:"songs" json:"songs"`
+}
+
+type options struct {
+	latency       time.Duration
+	jitter        time.Duration
+	faultDelay    time.Duration
+	notFoundRate  float64
+	errorRate     float64
+	malformedRate float64
+}
+
+type server struct {
+	log   *slog.Logger
+	opts  options
+	songs map[string]fixture
+}
+
+func main() {
+	addr := flag.String("addr", ":8081", "address to listen on")
+	path := flag.String("fixtures", "cmd/mockinfo/fixtures.yaml", "YAML or JSON file with songs")
+
+	var opts options
+	flag.DurationVar(&opts.latency, "latency", 0, "delay added to every response")
+	flag.DurationVar(&opts.jitter, "jitter", 0, "random extra delay up to this value")
+	flag.DurationVar(&opts.faultDelay, "fault-delay", 6*time.Second, "extra delay of the latency fault, keep it above the client timeout (5s by default)")
+	flag.Float64Var(&opts.notFoundRate, "not-found-rate", 0, "share of requests answered with 404")
+	flag.Float64Var(&opts.errorRate, "error-rate", 0, "share of requests answered with 500")
+	flag.Float64Var(&opts.malformedRate, "malformed-rate", 0, "share of requests answered with malformed JSON")
+	flag.Parse()
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	songs, err := loadFixtures(*path)
+	if err != nil {
+		log.Error("Failed to load fixtures", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	s := &server{log: log, opts: opts, songs: songs}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /info", s.info)
+
+	log.Info("Mock music info started", slog.String("addr", *addr), slog.Int("songs", len(songs)))
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		log.Error("Server stopped", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+}
+
+func loadFixtures(path string) (map[string]fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f fixtures
+	if strings.HasSuffix(path, ".json") {
+		err = json.Unmarshal(data, &f)
+	} else {
+		err = yaml.Unmarshal(data, &f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	songs := make(map[string]fixture, len(f.Songs))
+	for _, song := range f.Songs {
+		songs[key(song.Group, song.Song)] = song
+	}
+
+	return songs, nil
+}
+
+func key(group, song string) string {
+	return strings.ToLower(strings.TrimSpace(group)) + "\x00" + strings.ToLower(strings.TrimSpace(song))
+}
+
+func (s *server) info(w http.ResponseWriter, r *http.Request) {
+	group := r.URL.Query().Get("group")
+	song := r.URL.Query().Get("song")
+	log := s.log.With(slog.String("group", group), slog.String("song", song))
+
+	if group == "" || song == "" {
+		log.Info("Bad request")
+		http.Error(w, "group and song are required", http.StatusBadRequest)
+		return
+	}
+
+	found, ok := s.songs[key(group, song)]
+
+	fault := r.Header.Get("X-Mock-Fault")
+	if fault == faultNone {
+		fault = found.Fault
+	}
+	if fault == faultNone {
+		fault = s.randomFault()
+	}
+
+	s.delay(fault)
+
+	switch {
+	case fault == faultNotFound || (!ok && fault == faultNone):
+		log.Info("Not found", slog.String("fault", fault))
+		http.Error(w, "song not found", http.StatusNotFound)
+	case fault == faultError:
+		log.Info("Internal error", slog.String("fault", fault))
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	case fault == faultMalformed:
+		log.Info("Malformed response", slog.String("fault", fault))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"release_date": "16.07.2006", "text": `)
+	case !ok:
+		log.Info("Not found", slog.String("fault", fault))
+		http.Error(w, "song not found", http.StatusNotFound)
+	default:
+		log.Info("Found")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"release_date": found.ReleaseDate,
+			"text":         found.Text,
+			"link":         found.Link,
+		})
+	}
+}
+
+// randomFault picks a fault according to the configured rates.
+func (s *server) randomFault() string {
+	n := rand.Float64()
+
+	switch {
+	case n < s.opts.notFoundRate:
+		return faultNotFound
+	case n < s.opts.notFoundRate+s.opts.errorRate:
+		return faultError
+	case n < s.opts.notFoundRate+s.opts.errorRate+s.opts.malformedRate:
+		return faultMalformed
+	}
+
+	return faultNone
+}
+
+// delay sleeps for the configured latency. The latency fault adds the fault
+// delay on top, which by default outlasts the client timeout.
+func (s *server) delay(fault string) {
+	d := s.opts.latency
+	if s.opts.jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(s.opts.jitter)))
+	}
+	if fault == faultLatency {
+		d += s.opts.faultDelay
+	}
+
+	time.Sleep(d)
+}
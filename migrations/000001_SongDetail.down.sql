@@ -1,2 +0,0 @@
-DROP TABLE song;
-DROP TABLE songDetail;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS song_details;
+DROP TABLE IF EXISTS songs;
+DROP TABLE IF EXISTS groups;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS groups (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS songs (
+    id BIGSERIAL PRIMARY KEY,
+    song TEXT NOT NULL,
+    group_id BIGINT NOT NULL REFERENCES groups(id)
+);
+
+CREATE TABLE IF NOT EXISTS song_details (
+    id BIGSERIAL PRIMARY KEY,
+    song_id BIGINT NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    release_date TEXT,
+    text TEXT,
+    link TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_songs_song ON songs(song);
+CREATE INDEX IF NOT EXISTS idx_songs_group_id ON songs(group_id);
+CREATE INDEX IF NOT EXISTS idx_song_details_song_id ON song_details(song_id);
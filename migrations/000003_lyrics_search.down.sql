@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_song_details_search_vector;
+ALTER TABLE song_details DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,8 @@
+-- The catalog is mixed, so lyrics are indexed with both the Russian and the
+-- English configurations.
+ALTER TABLE song_details ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        to_tsvector('russian', coalesce(text, '')) || to_tsvector('english', coalesce(text, ''))
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_song_details_search_vector ON song_details USING GIN (search_vector);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_song_details_release_date;
+
+ALTER TABLE song_details ALTER COLUMN release_date TYPE TEXT USING to_char(release_date, 'DD.MM.YYYY');
@@ -0,0 +1,8 @@
+-- Release dates were stored as returned by the external API. Known formats
+-- are converted, anything else becomes NULL.
+ALTER TABLE song_details ALTER COLUMN release_date TYPE DATE USING (CASE
+    WHEN release_date ~ '^\d{2}\.\d{2}\.\d{4}$' THEN to_date(release_date, 'DD.MM.YYYY')
+    WHEN release_date ~ '^\d{4}-\d{2}-\d{2}$' THEN release_date::date
+END);
+
+CREATE INDEX IF NOT EXISTS idx_song_details_release_date ON song_details(release_date);
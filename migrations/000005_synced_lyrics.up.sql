@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS synced_lyrics (
+    song_id BIGINT PRIMARY KEY REFERENCES songs(id) ON DELETE CASCADE,
+    lrc TEXT NOT NULL,
+    updated_at TIMESTAMPTZ
+);
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS song_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    song_id BIGINT NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    revision BIGINT NOT NULL,
+    action TEXT NOT NULL,
+    author TEXT NOT NULL,
+    song TEXT NOT NULL,
+    group_id BIGINT NOT NULL,
+    release_date DATE,
+    text TEXT NOT NULL DEFAULT '',
+    link TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ,
+    UNIQUE (song_id, revision)
+);
+
+-- The history of the existing songs starts from their current state.
+INSERT INTO song_revisions (song_id, revision, action, author, song, group_id, release_date, text, link, created_at)
+SELECT DISTINCT ON (songs.id)
+    songs.id, 1, 'import', 'system', songs.song, songs.group_id,
+    song_details.release_date, COALESCE(song_details.text, ''), COALESCE(song_details.link, ''), now()
+FROM songs
+LEFT JOIN song_details ON song_details.song_id = songs.id
+ORDER BY songs.id, song_details.id DESC;
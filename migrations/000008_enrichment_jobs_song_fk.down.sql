@@ -0,0 +1 @@
+ALTER TABLE enrichment_jobs DROP CONSTRAINT IF EXISTS fk_enrichment_jobs_song;
@@ -0,0 +1,5 @@
+-- Jobs of songs that were already deleted would break the constraint.
+DELETE FROM enrichment_jobs WHERE song_id NOT IN (SELECT id FROM songs);
+
+ALTER TABLE enrichment_jobs
+    ADD CONSTRAINT fk_enrichment_jobs_song FOREIGN KEY (song_id) REFERENCES songs(id) ON DELETE CASCADE;
@@ -0,0 +1,152 @@
+// Package logger builds the slog.Logger of the service.
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+
+	redacted = "[REDACTED]"
+)
+
+type Options struct {
+	Level  slog.Level
+	Format string
+	// Output is stdout, stderr or a file path. Files are rotated once they
+	// reach MaxSizeMB, keeping MaxBackups files for at most MaxAgeDays.
+	Output     string
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+	Compress   bool
+	// Redact lists the attribute keys, case insensitive, whose values are
+	// never written.
+	Redact []string
+	// MaxAttrSize truncates the values longer than that many bytes, 0
+	// disables it.
+	MaxAttrSize int
+}
+
+// New returns the logger described by opts and the writer to close on exit.
+func New(opts Options) (*slog.Logger, io.Closer, error) {
+	var out io.WriteCloser
+	switch opts.Output {
+	case OutputStdout, "":
+		out = nopCloser{os.Stdout}
+	case OutputStderr:
+		out = nopCloser{os.Stderr}
+	default:
+		out = &lumberjack.Logger{
+			Filename:   opts.Output,
+			MaxSize:    opts.MaxSizeMB,
+			MaxBackups: opts.MaxBackups,
+			MaxAge:     opts.MaxAgeDays,
+			Compress:   opts.Compress,
+		}
+	}
+
+	handlerOpts := &slog.HandlerOptions{
+		Level:       opts.Level,
+		ReplaceAttr: replaceAttr(opts.Redact, opts.MaxAttrSize),
+	}
+
+	var handler slog.Handler
+	switch opts.Format {
+	case FormatJSON, "":
+		handler = slog.NewJSONHandler(out, handlerOpts)
+	case FormatText:
+		handler = slog.NewTextHandler(out, handlerOpts)
+	default:
+		return nil, nil, fmt.Errorf("unknown log format %q", opts.Format)
+	}
+
+	return slog.New(handler), out, nil
+}
+
+// ParseLevel parses debug, info, warn or error, case insensitive.
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q", s)
+	}
+	return level, nil
+}
+
+// replaceAttr redacts the attributes named in redact and truncates the values
+// longer than maxSize. The built-in time, level and message keys are left as
+// they are.
+func replaceAttr(redact []string, maxSize int) func(groups []string, a slog.Attr) slog.Attr {
+	keys := make(map[string]struct{}, len(redact))
+	for _, key := range redact {
+		keys[strings.ToLower(key)] = struct{}{}
+	}
+
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 {
+			switch a.Key {
+			case slog.TimeKey, slog.LevelKey, slog.MessageKey:
+				return a
+			}
+		}
+
+		if _, ok := keys[strings.ToLower(a.Key)]; ok {
+			return slog.String(a.Key, redacted)
+		}
+
+		if maxSize <= 0 {
+			return a
+		}
+
+		switch a.Value.Kind() {
+		case slog.KindString:
+			if s := a.Value.String(); len(s) > maxSize {
+				return slog.String(a.Key, truncate(s, maxSize))
+			}
+		case slog.KindAny:
+			// Structs and slices are measured as they would be written.
+			if err, ok := a.Value.Any().(error); ok {
+				if s := err.Error(); len(s) > maxSize {
+					return slog.String(a.Key, truncate(s, maxSize))
+				}
+				return a
+			}
+
+			b, err := json.Marshal(a.Value.Any())
+			if err == nil && len(b) > maxSize {
+				return slog.String(a.Key, truncate(string(b), maxSize))
+			}
+		}
+
+		return a
+	}
+}
+
+// truncate cuts s to at most maxSize bytes without splitting a rune and notes
+// how long it was.
+func truncate(s string, maxSize int) string {
+	cut := maxSize
+	for cut > 0 && cut < len(s) && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s…(%d bytes)", s[:cut], len(s))
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
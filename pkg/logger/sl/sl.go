@@ -1,10 +1,34 @@
 package sl
 
-import "log/slog"
+import (
+	"log/slog"
+	"time"
+)
 
+// Err returns err under the error key, nil errors are left out of the line.
 func Err(err error) slog.Attr {
+	if err == nil {
+		return slog.Attr{}
+	}
+
 	return slog.Attr{
 		Key:   "error",
 		Value: slog.StringValue(err.Error()),
 	}
 }
+
+// Duration returns d in milliseconds under key, as in duration_ms.
+func Duration(key string, d time.Duration) slog.Attr {
+	return slog.Float64(key+"_ms", float64(d.Microseconds())/1000)
+}
+
+// ID returns the id of an entity of the given kind, as in song_id.
+func ID(kind string, id int) slog.Attr {
+	return slog.Int(kind+"_id", id)
+}
+
+// Entity returns a summary of an entity of the given kind: its id and name,
+// never its whole content.
+func Entity(kind string, id int, name string) slog.Attr {
+	return slog.Group(kind, slog.Int("id", id), slog.String("name", name))
+}